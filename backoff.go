@@ -0,0 +1,66 @@
+package starfighter
+
+import (
+	"math/rand"
+	"time"
+)
+
+// Jitter is how retry delays are randomized, so that bots that fail at the
+// same moment (say, a few sharing an IP) don't all retry at the same moment
+// too. They're the ones from the AWS Architecture Blog's "Exponential
+// Backoff And Jitter".
+type Jitter int
+
+const (
+	// NoJitter doubles the delay each time, exactly.
+	NoJitter Jitter = iota
+	// FullJitter waits anywhere between nothing and the doubled delay.
+	FullJitter
+	// EqualJitter waits at least half the doubled delay, and up to all of it.
+	EqualJitter
+	// DecorrelatedJitter waits anywhere between the base delay and three
+	// times the last one, so delays grow without lining up with anyone's.
+	DecorrelatedJitter
+)
+
+// backoff works out the delays before each retry: base to start with, never
+// more than max.
+type backoff struct {
+	jitter Jitter
+	base   time.Duration
+	max    time.Duration
+	prev   time.Duration
+}
+
+func newBackoff(jitter Jitter, base, max time.Duration) *backoff {
+	return &backoff{jitter: jitter, base: base, max: max, prev: base}
+}
+
+// next is the delay before retry number attempt (from 0).
+func (b *backoff) next(attempt int) time.Duration {
+	if b.jitter == DecorrelatedJitter {
+		b.prev = min(b.max, between(b.base, 3*b.prev))
+		return b.prev
+	}
+
+	exp := b.max
+	if attempt < 32 && b.base<<uint(attempt) < b.max {
+		exp = b.base << uint(attempt)
+	}
+
+	switch b.jitter {
+	case FullJitter:
+		return between(0, exp)
+	case EqualJitter:
+		return exp/2 + between(0, exp/2)
+	}
+	return exp
+}
+
+// between is a random duration from lo to hi, inclusive.
+func between(lo, hi time.Duration) time.Duration {
+	if hi <= lo {
+		return lo
+	}
+	return lo + time.Duration(rand.Int63n(int64(hi-lo)+1))
+}
@@ -0,0 +1,43 @@
+package starfighter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBackoffJitter(t *testing.T) {
+	const base, max = 100 * time.Millisecond, 2 * time.Second
+
+	// the un-jittered delays: 100ms, 200ms, ... capped at 2s
+	exp := func(attempt int) time.Duration {
+		return min(max, base<<uint(attempt))
+	}
+
+	for run := 0; run < 100; run++ {
+		for _, jitter := range []Jitter{NoJitter, FullJitter, EqualJitter, DecorrelatedJitter} {
+			b := newBackoff(jitter, base, max)
+			prev := base
+
+			for attempt := 0; attempt < 10; attempt++ {
+				delay := b.next(attempt)
+
+				var lo, hi time.Duration
+				switch jitter {
+				case NoJitter:
+					lo, hi = exp(attempt), exp(attempt)
+				case FullJitter:
+					lo, hi = 0, exp(attempt)
+				case EqualJitter:
+					lo, hi = exp(attempt)/2, exp(attempt)
+				case DecorrelatedJitter:
+					lo, hi = base, min(max, 3*prev)
+				}
+
+				if delay < lo || delay > hi {
+					t.Fatalf("jitter %d, attempt %d: expected %s-%s, got %s", jitter, attempt, lo, hi, delay)
+				}
+				prev = delay
+			}
+		}
+	}
+}
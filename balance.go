@@ -0,0 +1,70 @@
+package starfighter
+
+import (
+	"context"
+	"sort"
+)
+
+// Balance is what an account is worth on a venue, in cents.
+type Balance struct {
+	// Cash on hand, which goes negative if you've bought on margin
+	Cash int64 `json:"cash"`
+	// Shares held by symbol, negative for shorts
+	Positions map[string]int `json:"positions"`
+	// Positions marked at each symbol's last trade
+	PositionValue int64 `json:"positionValue"`
+}
+
+// NAV is cash plus the value of the positions.
+func (b *Balance) NAV() int64 {
+	return b.Cash + b.PositionValue
+}
+
+// AccountBalance works out the account's balance on the venue. Neither API
+// has an endpoint for it, so it's the client's StartingCapital, less what
+// the account's fills cost, plus the positions marked at the last trade.
+func (c *Client) AccountBalance(venue, account string) (*Balance, error) {
+	return c.accountBalance(context.Background(), venue, account)
+}
+
+func (c *Client) accountBalance(ctx context.Context, venue, account string) (*Balance, error) {
+	orders, err := c.listVenueOrderStatus(ctx, venue, account)
+	if err != nil {
+		return nil, err
+	}
+
+	balance := &Balance{
+		Cash:      c.StartingCapital,
+		Positions: map[string]int{},
+	}
+
+	for _, order := range orders.Orders {
+		sign := 1
+		if order.Direction == Sell {
+			sign = -1
+		}
+
+		for _, fill := range order.Fills {
+			balance.Cash -= int64(sign * fill.Qty * fill.Price)
+			balance.Positions[order.Symbol] += sign * fill.Qty
+		}
+	}
+
+	symbols := make([]string, 0, len(balance.Positions))
+	for symbol, qty := range balance.Positions {
+		if qty != 0 {
+			symbols = append(symbols, symbol)
+		}
+	}
+	sort.Strings(symbols)
+
+	for _, symbol := range symbols {
+		quote, err := c.quoteStock(ctx, venue, symbol)
+		if err != nil {
+			return nil, err
+		}
+		balance.PositionValue += int64(balance.Positions[symbol] * quote.Last)
+	}
+
+	return balance, nil
+}
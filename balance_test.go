@@ -0,0 +1,56 @@
+package starfighter
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestBalanceDecode(t *testing.T) {
+	balance := Balance{}
+	err := json.Unmarshal([]byte(`{"cash": -12000, "positions": {"FOOBAR": 10, "BAZ": -2}, "positionValue": 15000}`), &balance)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if balance.Cash != -12000 || balance.PositionValue != 15000 || balance.NAV() != 3000 {
+		t.Errorf("unexpected balance %+v", balance)
+	}
+	if balance.Positions["FOOBAR"] != 10 || balance.Positions["BAZ"] != -2 {
+		t.Errorf("unexpected positions %v", balance.Positions)
+	}
+}
+
+func TestAccountBalance(t *testing.T) {
+	c, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/quote") {
+			fmt.Fprintf(w, `{"ok": true, "symbol": %q, "last": 120}`, TestStock)
+			return
+		}
+
+		fmt.Fprintf(w, `{"ok": true, "orders": [
+			{"symbol": %[1]q, "direction": "buy", "fills": [{"price": 100, "qty": 10}, {"price": 110, "qty": 5}]},
+			{"symbol": %[1]q, "direction": "sell", "fills": [{"price": 130, "qty": 3}]}
+		]}`, TestStock)
+	})
+	defer server.Close()
+	c.StartingCapital = 10000
+
+	balance, err := c.AccountBalance(TestExchange, TestAccount)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 10000 - 1000 - 550 + 390
+	if balance.Cash != 8840 {
+		t.Errorf("expected cash 8840, got %d", balance.Cash)
+	}
+	if balance.Positions[TestStock] != 12 || balance.PositionValue != 1440 {
+		t.Errorf("expected 12 shares worth 1440, got %d worth %d", balance.Positions[TestStock], balance.PositionValue)
+	}
+	if balance.NAV() != 10280 {
+		t.Errorf("expected NAV 10280, got %d", balance.NAV())
+	}
+}
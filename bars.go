@@ -0,0 +1,45 @@
+package starfighter
+
+import (
+	"sort"
+	"time"
+)
+
+// Bar is the trading in one interval, for candlestick charts and the like.
+// Prices are in cents.
+type Bar struct {
+	Start  time.Time
+	Open   int
+	High   int
+	Low    int
+	Close  int
+	Volume int
+}
+
+// BuildBars groups the executions into OHLCV bars, one per interval that
+// had any trading, oldest first. Bars start on multiples of interval (since
+// the zero time), so 1 minute bars start on the minute. The executions
+// don't have to be in order.
+func BuildBars(execs []Execution, interval time.Duration) []Bar {
+	sorted := append([]Execution(nil), execs...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].FilledAt.Before(sorted[j].FilledAt)
+	})
+
+	bars := []Bar{}
+	for _, execution := range sorted {
+		start := execution.FilledAt.Truncate(interval)
+
+		if len(bars) == 0 || !bars[len(bars)-1].Start.Equal(start) {
+			bars = append(bars, Bar{Start: start, Open: execution.Price, High: execution.Price, Low: execution.Price})
+		}
+
+		bar := &bars[len(bars)-1]
+		bar.High = max(bar.High, execution.Price)
+		bar.Low = min(bar.Low, execution.Price)
+		bar.Close = execution.Price
+		bar.Volume += execution.Filled
+	}
+
+	return bars
+}
@@ -0,0 +1,44 @@
+package starfighter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBuildBars(t *testing.T) {
+	start := time.Date(2016, 1, 1, 12, 0, 0, 0, time.UTC)
+	at := func(seconds int) time.Time {
+		return start.Add(time.Duration(seconds) * time.Second)
+	}
+
+	// out of order, and nothing between 12:01 and 12:03
+	execs := []Execution{
+		{Price: 101, Filled: 5, FilledAt: at(10)},
+		{Price: 100, Filled: 10, FilledAt: at(0)},
+		{Price: 104, Filled: 2, FilledAt: at(30)},
+		{Price: 99, Filled: 3, FilledAt: at(59)},
+		{Price: 98, Filled: 7, FilledAt: at(60)},
+		{Price: 110, Filled: 1, FilledAt: at(185)},
+		{Price: 108, Filled: 4, FilledAt: at(190)},
+	}
+
+	bars := BuildBars(execs, time.Minute)
+	expected := []Bar{
+		{start, 100, 104, 99, 99, 20},
+		{at(60), 98, 98, 98, 98, 7},
+		{at(180), 110, 110, 108, 108, 5},
+	}
+
+	if len(bars) != len(expected) {
+		t.Fatalf("expected %d bars, got %+v", len(expected), bars)
+	}
+	for k, bar := range bars {
+		if bar != expected[k] {
+			t.Errorf("bar %d: expected %+v, got %+v", k, expected[k], bar)
+		}
+	}
+
+	if bars := BuildBars(nil, time.Minute); len(bars) != 0 {
+		t.Errorf("expected no bars, got %+v", bars)
+	}
+}
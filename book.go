@@ -0,0 +1,212 @@
+package starfighter
+
+import "sort"
+
+// against returns the side of the book an order in direction trades
+// against, best price first.
+func (b *OrderBook) against(direction Direction) []BookEntry {
+	if direction == Sell {
+		return b.sorted(Buy)
+	}
+	return b.sorted(Sell)
+}
+
+// sorted returns a copy of one side of the book (Buy for bids, Sell for
+// asks), best price first. The API sends them that way, but let's not count
+// on it. Entries with nothing in them are left out.
+func (b *OrderBook) sorted(side Direction) []BookEntry {
+	all := b.Asks
+	if side == Buy {
+		all = b.Bids
+	}
+
+	entries := []BookEntry{}
+	for _, entry := range all {
+		if entry.Qty > 0 {
+			entries = append(entries, entry)
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		if side == Buy {
+			return entries[i].Price > entries[j].Price
+		}
+		return entries[i].Price < entries[j].Price
+	})
+
+	return entries
+}
+
+// PriceToFill is the worst price an order in direction side has to go to
+// in order to fill qty shares by sweeping the other side of the book (the
+// asks, for a buy). If the book isn't deep enough, ok is false, and filled
+// and price say how much could be had and how far it went.
+func (b *OrderBook) PriceToFill(qty int, side Direction) (price int, filled int, ok bool) {
+	price, filled, _ = b.sweep(qty, side)
+	return price, filled, filled == qty && qty > 0
+}
+
+// CrossingCost is what it costs, in cents, to get qty shares right now by
+// sweeping the other side of the book, along with the average price paid
+// (or received, for a sell). If the book isn't deep enough, ok is false and
+// the rest describes the shares that could be had.
+func (b *OrderBook) CrossingCost(qty int, side Direction) (costCents int, avgPrice float64, filled int, ok bool) {
+	_, filled, costCents = b.sweep(qty, side)
+	if filled > 0 {
+		avgPrice = float64(costCents) / float64(filled)
+	}
+	return costCents, avgPrice, filled, filled == qty && qty > 0
+}
+
+// sweep takes up to qty shares from the other side of the book, best price
+// first, and says how far it went, how much it got and what that cost.
+func (b *OrderBook) sweep(qty int, side Direction) (price, filled, cost int) {
+	for _, entry := range b.against(side) {
+		if filled >= qty {
+			break
+		}
+
+		taken := min(entry.Qty, qty-filled)
+		price = entry.Price
+		filled += taken
+		cost += taken * entry.Price
+	}
+
+	return price, filled, cost
+}
+
+// QueueAhead is how many shares are already resting at price on the side of
+// the book an order in direction side would join (the bids, for a buy), and
+// so are ahead of it in the queue. It's 0 if nobody's there.
+func (b *OrderBook) QueueAhead(price int, side Direction) int {
+	entries := b.Asks
+	if side == Buy {
+		entries = b.Bids
+	}
+
+	ahead := 0
+	for _, entry := range entries {
+		if entry.Price == price {
+			ahead += entry.Qty
+		}
+	}
+
+	return ahead
+}
+
+// Notional is the value of everything resting on one side of the book (Buy
+// for bids, Sell for asks) in cents: price times qty summed over the
+// levels. It's 0 for an empty side.
+func (b *OrderBook) Notional(side Direction) int {
+	notional := 0
+	for _, entry := range b.sorted(side) {
+		notional += entry.Price * entry.Qty
+	}
+	return notional
+}
+
+// IsEmpty says whether there's nothing resting on either side. A venue that
+// has only just opened sends an empty book with a zero Timestamp, so check
+// HasData too to tell "no data yet" from "no liquidity". The helpers treat
+// an empty book the same either way: there's nothing to fill against, so
+// they come back not ok, and it's neither crossed nor locked.
+func (b *OrderBook) IsEmpty() bool {
+	return len(b.sorted(Buy)) == 0 && len(b.sorted(Sell)) == 0
+}
+
+// HasData says whether the venue has sent a book yet, going by whether it
+// has a Timestamp. An empty book that has data has no liquidity.
+func (b *OrderBook) HasData() bool {
+	return !b.Timestamp.IsZero()
+}
+
+// touch is the best bid and ask prices, if there's anything on both sides.
+func (b *OrderBook) touch() (bid, ask int, ok bool) {
+	bids, asks := b.sorted(Buy), b.sorted(Sell)
+	if len(bids) == 0 || len(asks) == 0 {
+		return 0, 0, false
+	}
+	return bids[0].Price, asks[0].Price, true
+}
+
+// IsCrossed says whether the best bid is at or above the best ask, which
+// means the data is stale or there's money lying on the table.
+// It's false if either side is empty.
+func (b *OrderBook) IsCrossed() bool {
+	bid, ask, ok := b.touch()
+	return ok && bid >= ask
+}
+
+// IsLocked says whether the best bid and ask are the same price.
+// It's false if either side is empty.
+func (b *OrderBook) IsLocked() bool {
+	bid, ask, ok := b.touch()
+	return ok && bid == ask
+}
+
+// FairValue is a guess at what the stock's really worth from the top depth
+// price levels of each side. It's the micro-price, except with the VWAP of
+// each side's levels standing in for its best price: each side's price is
+// weighted by the qty on the other side, so the price leans towards the
+// thinner side, which is the one more likely to give. At a depth of 1 it's
+// the plain micro-price, which thin books can throw around; looking deeper
+// steadies it. It's false if either side is empty.
+func (b *OrderBook) FairValue(depth int) (float64, bool) {
+	bidPrice, bidQty := levelsVWAP(b.sorted(Buy), depth)
+	askPrice, askQty := levelsVWAP(b.sorted(Sell), depth)
+	if bidQty == 0 || askQty == 0 {
+		return 0, false
+	}
+
+	return (bidPrice*float64(askQty) + askPrice*float64(bidQty)) / float64(bidQty+askQty), true
+}
+
+// levelsVWAP is the VWAP and total qty of the first levels price levels of
+// entries, which should be sorted best first.
+func levelsVWAP(entries []BookEntry, levels int) (float64, int) {
+	notional, qty, seen := 0, 0, 0
+	for k, entry := range entries {
+		if k == 0 || entry.Price != entries[k-1].Price {
+			if seen++; seen > levels {
+				break
+			}
+		}
+		notional += entry.Price * entry.Qty
+		qty += entry.Qty
+	}
+
+	if qty == 0 {
+		return 0, 0
+	}
+	return float64(notional) / float64(qty), qty
+}
+
+// DepthPoint is one price level of a depth chart: the price, and the total
+// qty from the touch out to and including it.
+type DepthPoint struct {
+	Price int `json:"price"`
+	Qty   int `json:"qty"`
+}
+
+// DepthChart is the book as cumulative depth, one point per price level,
+// ready to plot. Each side starts at the touch and works outwards, so bids
+// go down in price and asks go up.
+func (b *OrderBook) DepthChart() (bidLevels, askLevels []DepthPoint) {
+	return depth(b.sorted(Buy)), depth(b.sorted(Sell))
+}
+
+func depth(entries []BookEntry) []DepthPoint {
+	points := []DepthPoint{}
+	total := 0
+
+	for _, entry := range entries {
+		total += entry.Qty
+		if n := len(points); n > 0 && points[n-1].Price == entry.Price {
+			points[n-1].Qty = total
+			continue
+		}
+		points = append(points, DepthPoint{Price: entry.Price, Qty: total})
+	}
+
+	return points
+}
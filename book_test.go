@@ -0,0 +1,230 @@
+package starfighter
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func testBook() *OrderBook {
+	return &OrderBook{
+		Bids: []BookEntry{
+			{IsBuy: true, Price: 100, Qty: 10},
+			{IsBuy: true, Price: 99, Qty: 20},
+			{IsBuy: true, Price: 97, Qty: 30},
+		},
+		Asks: []BookEntry{
+			{Price: 102, Qty: 5},
+			{Price: 103, Qty: 15},
+			{Price: 105, Qty: 25},
+		},
+	}
+}
+
+func TestPriceToFill(t *testing.T) {
+	book := testBook()
+
+	for _, c := range []struct {
+		qty    int
+		side   Direction
+		price  int
+		filled int
+		ok     bool
+	}{
+		{5, Buy, 102, 5, true},
+		{6, Buy, 103, 6, true},
+		{45, Buy, 105, 45, true},
+		{50, Buy, 105, 45, false},
+		{10, Sell, 100, 10, true},
+		{31, Sell, 97, 31, true},
+		{100, Sell, 97, 60, false},
+	} {
+		price, filled, ok := book.PriceToFill(c.qty, c.side)
+		if price != c.price || filled != c.filled || ok != c.ok {
+			t.Errorf("%s %d: expected (%d, %d, %v), got (%d, %d, %v)",
+				c.side, c.qty, c.price, c.filled, c.ok, price, filled, ok)
+		}
+	}
+
+	if price, filled, ok := (&OrderBook{}).PriceToFill(10, Buy); price != 0 || filled != 0 || ok {
+		t.Errorf("expected nothing from an empty book, got (%d, %d, %v)", price, filled, ok)
+	}
+}
+
+func TestQueueAhead(t *testing.T) {
+	book := testBook()
+	book.Bids = append(book.Bids, BookEntry{IsBuy: true, Price: 99, Qty: 5})
+
+	for _, c := range []struct {
+		price int
+		side  Direction
+		ahead int
+	}{
+		{100, Buy, 10},
+		{99, Buy, 25},
+		{98, Buy, 0},
+		{103, Sell, 15},
+		{104, Sell, 0},
+		{100, Sell, 0},
+	} {
+		if ahead := book.QueueAhead(c.price, c.side); ahead != c.ahead {
+			t.Errorf("%s @ %d: expected %d ahead, got %d", c.side, c.price, c.ahead, ahead)
+		}
+	}
+}
+
+func TestNotional(t *testing.T) {
+	book := testBook()
+
+	// 100*10 + 99*20 + 97*30 and 102*5 + 103*15 + 105*25
+	if notional := book.Notional(Buy); notional != 5890 {
+		t.Errorf("expected 5890 bid, got %d", notional)
+	}
+	if notional := book.Notional(Sell); notional != 4680 {
+		t.Errorf("expected 4680 asked, got %d", notional)
+	}
+
+	book.Asks = nil
+	if notional := book.Notional(Sell); notional != 0 {
+		t.Errorf("expected nothing asked, got %d", notional)
+	}
+}
+
+func TestFairValue(t *testing.T) {
+	book := testBook()
+	// a second order at the best bid is still the one level
+	book.Bids = append(book.Bids, BookEntry{IsBuy: true, Price: 100, Qty: 5})
+
+	// the micro-price: 100 weighted by the 5 asked, 102 by the 15 bid
+	micro, ok := book.FairValue(1)
+	if !ok || math.Abs(micro-(100*5+102*15)/20.0) > 1e-9 {
+		t.Errorf("expected the micro-price %v, got (%v, %v)", (100*5+102*15)/20.0, micro, ok)
+	}
+
+	// bids 6390/65 weighted by the 45 asked, asks 104 by the 65 bid
+	expected := (6390.0/65*45 + 104*65) / 110
+	fair, ok := book.FairValue(3)
+	if !ok || math.Abs(fair-expected) > 1e-9 {
+		t.Errorf("expected %v, got (%v, %v)", expected, fair, ok)
+	}
+	if fair == micro {
+		t.Errorf("expected looking deeper to move it off the micro-price %v", micro)
+	}
+
+	book.Asks = nil
+	if _, ok := book.FairValue(3); ok {
+		t.Error("expected no fair value with no asks")
+	}
+}
+
+func TestBookCrossedLocked(t *testing.T) {
+	for _, c := range []struct {
+		name            string
+		bid, ask        int
+		crossed, locked bool
+	}{
+		{"normal", 100, 102, false, false},
+		{"locked", 101, 101, true, true},
+		{"crossed", 103, 101, true, false},
+	} {
+		book := &OrderBook{
+			Bids: []BookEntry{{Price: c.bid - 5, Qty: 1}, {Price: c.bid, Qty: 1}},
+			Asks: []BookEntry{{Price: c.ask, Qty: 1}, {Price: c.ask + 5, Qty: 1}},
+		}
+
+		if book.IsCrossed() != c.crossed || book.IsLocked() != c.locked {
+			t.Errorf("%s: expected crossed %v, locked %v", c.name, c.crossed, c.locked)
+		}
+	}
+
+	oneSided := &OrderBook{Bids: []BookEntry{{Price: 100, Qty: 1}}}
+	if oneSided.IsCrossed() || oneSided.IsLocked() {
+		t.Error("expected a one-sided book to be neither crossed nor locked")
+	}
+}
+
+func TestCrossingCost(t *testing.T) {
+	book := testBook()
+
+	// 5 @ 102 + 15 @ 103 + 5 @ 105
+	cost, avg, filled, ok := book.CrossingCost(25, Buy)
+	if cost != 2580 || avg != 103.2 || filled != 25 || !ok {
+		t.Errorf("expected (2580, 103.2, 25, true), got (%d, %v, %d, %v)", cost, avg, filled, ok)
+	}
+
+	// 10 @ 100 + 20 @ 99 + 30 @ 97, and 40 more that aren't there
+	cost, avg, filled, ok = book.CrossingCost(100, Sell)
+	if cost != 5890 || avg != 5890.0/60 || filled != 60 || ok {
+		t.Errorf("expected (5890, %v, 60, false), got (%d, %v, %d, %v)", 5890.0/60, cost, avg, filled, ok)
+	}
+
+	cost, avg, filled, ok = (&OrderBook{}).CrossingCost(10, Buy)
+	if cost != 0 || avg != 0 || filled != 0 || ok {
+		t.Errorf("expected nothing from an empty book, got (%d, %v, %d, %v)", cost, avg, filled, ok)
+	}
+}
+
+func TestDepthChart(t *testing.T) {
+	book := testBook()
+	// a second order at an existing level, out of order
+	book.Asks = append(book.Asks, BookEntry{Price: 103, Qty: 10})
+
+	bids, asks := book.DepthChart()
+
+	expectedBids := []DepthPoint{{100, 10}, {99, 30}, {97, 60}}
+	expectedAsks := []DepthPoint{{102, 5}, {103, 30}, {105, 55}}
+
+	if !reflect.DeepEqual(bids, expectedBids) {
+		t.Errorf("expected bids %v, got %v", expectedBids, bids)
+	}
+	if !reflect.DeepEqual(asks, expectedAsks) {
+		t.Errorf("expected asks %v, got %v", expectedAsks, asks)
+	}
+
+	bids, asks = (&OrderBook{}).DepthChart()
+	if len(bids) != 0 || len(asks) != 0 {
+		t.Errorf("expected an empty chart for an empty book, got %v and %v", bids, asks)
+	}
+}
+
+func TestEmptyOrderBook(t *testing.T) {
+	for _, book := range []*OrderBook{
+		{},
+		{Bids: []BookEntry{}, Asks: []BookEntry{}},
+		{Bids: []BookEntry{{IsBuy: true, Price: 100, Qty: 0}}, Asks: []BookEntry{{Price: 100, Qty: 0}}},
+	} {
+		if !book.IsEmpty() {
+			t.Errorf("%+v: expected IsEmpty", book)
+		}
+		if book.HasData() {
+			t.Errorf("%+v: expected no data yet", book)
+		}
+
+		if _, _, ok := book.PriceToFill(10, Buy); ok {
+			t.Errorf("%+v: expected PriceToFill not to be ok", book)
+		}
+		if _, _, filled, ok := book.CrossingCost(10, Sell); ok || filled != 0 {
+			t.Errorf("%+v: expected CrossingCost not to be ok", book)
+		}
+		if book.IsCrossed() || book.IsLocked() {
+			t.Errorf("%+v: expected an empty book to be neither crossed nor locked", book)
+		}
+		if bids, asks := book.DepthChart(); len(bids) != 0 || len(asks) != 0 {
+			t.Errorf("%+v: expected an empty depth chart", book)
+		}
+	}
+
+	if testBook().IsEmpty() {
+		t.Error("expected the test book not to be empty")
+	}
+	if (&OrderBook{Asks: []BookEntry{{Price: 100, Qty: 1}}}).IsEmpty() {
+		t.Error("expected a one-sided book not to be empty")
+	}
+
+	// an empty book from a venue that has sent one is no liquidity
+	drained := &OrderBook{Timestamp: time.Date(2016, 1, 1, 12, 0, 0, 0, time.UTC)}
+	if !drained.IsEmpty() || !drained.HasData() {
+		t.Error("expected an empty book with a timestamp to be empty but have data")
+	}
+}
@@ -0,0 +1,69 @@
+package starfighter
+
+import "net/http"
+
+// cachedOrderbook is an orderbook and what's needed to ask the API whether
+// it's changed.
+type cachedOrderbook struct {
+	etag         string
+	lastModified string
+	book         OrderBook
+}
+
+// setValidators makes req conditional on the book having changed.
+func (b *cachedOrderbook) setValidators(req *http.Request) {
+	if b == nil {
+		return
+	}
+
+	if b.etag != "" {
+		req.Header.Set("If-None-Match", b.etag)
+	}
+	if b.lastModified != "" {
+		req.Header.Set("If-Modified-Since", b.lastModified)
+	}
+}
+
+// Book returns a copy of the cached book, so the cache can't be changed
+// through it.
+func (b *cachedOrderbook) Book() *OrderBook {
+	return copyOrderBook(&b.book)
+}
+
+func copyOrderBook(b *OrderBook) *OrderBook {
+	book := *b
+	book.Asks = append([]BookEntry(nil), b.Asks...)
+	book.Bids = append([]BookEntry(nil), b.Bids...)
+	return &book
+}
+
+func (c *Client) cachedOrderbook(venue, stock string) *cachedOrderbook {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.orderbooks[venue+"/"+stock]
+}
+
+// cacheOrderbook keeps the book if the response said how to revalidate it.
+// If the API doesn't support that, nothing is cached and every fetch is a
+// full one.
+func (c *Client) cacheOrderbook(venue, stock string, resp *http.Response, book *OrderBook) {
+	cached := &cachedOrderbook{
+		etag:         resp.Header.Get("ETag"),
+		lastModified: resp.Header.Get("Last-Modified"),
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if cached.etag == "" && cached.lastModified == "" {
+		delete(c.orderbooks, venue+"/"+stock)
+		return
+	}
+
+	cached.book = *copyOrderBook(book)
+
+	if c.orderbooks == nil {
+		c.orderbooks = map[string]*cachedOrderbook{}
+	}
+	c.orderbooks[venue+"/"+stock] = cached
+}
@@ -0,0 +1,61 @@
+package starfighter
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestGetStockOrderbookNotModified(t *testing.T) {
+	calls := 0
+	c, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		w.Header().Set("ETag", `"v1"`)
+		fmt.Fprint(w, `{"ok": true, "symbol": "FOOBAR", "bids": [{"price": 100, "qty": 10, "isBuy": true}], "asks": [{"price": 105, "qty": 5}]}`)
+	})
+	defer server.Close()
+
+	first, err := c.GetStockOrderbook(TestExchange, TestStock)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// scribbling on what we got back shouldn't touch the cache
+	want := *first
+	want.Bids = append([]BookEntry(nil), first.Bids...)
+	first.Bids[0].Qty = 999
+
+	second, err := c.GetStockOrderbook(TestExchange, TestStock)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if calls != 2 {
+		t.Errorf("expected 2 calls, got %d", calls)
+	}
+	if !reflect.DeepEqual(*second, want) {
+		t.Errorf("expected the cached book %+v, got %+v", want, *second)
+	}
+}
+
+func TestGetStockOrderbookNoValidators(t *testing.T) {
+	c, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") != "" || r.Header.Get("If-Modified-Since") != "" {
+			t.Errorf("expected an unconditional request, got %v", r.Header)
+		}
+		fmt.Fprint(w, `{"ok": true, "symbol": "FOOBAR"}`)
+	})
+	defer server.Close()
+
+	for i := 0; i < 2; i++ {
+		if _, err := c.GetStockOrderbook(TestExchange, TestStock); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package starfighter
+
+import (
+	"sync"
+	"time"
+)
+
+// cadence is the executions seen so far for one symbol.
+type cadence struct {
+	last  time.Time
+	total time.Duration
+	count int
+}
+
+// ExecutionCadence keeps the average time between executions of each
+// symbol, as a rough guess at how long a resting order will wait for a
+// fill. Feed it from the executions feed; it's safe to do so from several
+// goroutines.
+type ExecutionCadence struct {
+	mu      sync.Mutex
+	symbols map[string]*cadence
+}
+
+// NewExecutionCadence creates an empty ExecutionCadence.
+func NewExecutionCadence() *ExecutionCadence {
+	return &ExecutionCadence{symbols: map[string]*cadence{}}
+}
+
+// AddExecution counts the execution. Ones older than the latest we've seen
+// for the symbol are late and are ignored.
+func (e *ExecutionCadence) AddExecution(execution Execution) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	s, ok := e.symbols[execution.Symbol]
+	if !ok {
+		e.symbols[execution.Symbol] = &cadence{last: execution.FilledAt}
+		return
+	}
+
+	if execution.FilledAt.Before(s.last) {
+		return
+	}
+
+	s.total += execution.FilledAt.Sub(s.last)
+	s.count++
+	s.last = execution.FilledAt
+}
+
+// ExpectedTimeToFill is the average time between the symbol's executions,
+// or 0 if there haven't been two yet.
+func (e *ExecutionCadence) ExpectedTimeToFill(symbol string) time.Duration {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	s, ok := e.symbols[symbol]
+	if !ok || s.count == 0 {
+		return 0
+	}
+	return s.total / time.Duration(s.count)
+}
@@ -0,0 +1,38 @@
+package starfighter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExecutionCadence(t *testing.T) {
+	start := time.Date(2016, 1, 1, 12, 0, 0, 0, time.UTC)
+	at := func(symbol string, d time.Duration) Execution {
+		return Execution{Symbol: symbol, FilledAt: start.Add(d)}
+	}
+
+	e := NewExecutionCadence()
+	if d := e.ExpectedTimeToFill(TestStock); d != 0 {
+		t.Errorf("expected 0 with nothing seen, got %s", d)
+	}
+
+	e.AddExecution(at(TestStock, 0))
+	if d := e.ExpectedTimeToFill(TestStock); d != 0 {
+		t.Errorf("expected 0 after one execution, got %s", d)
+	}
+
+	e.AddExecution(at(TestStock, 2*time.Second))
+	e.AddExecution(at("BAZ", time.Second))
+	e.AddExecution(at(TestStock, 3*time.Second))
+	e.AddExecution(at(TestStock, time.Second)) // late, ignored
+	e.AddExecution(at(TestStock, 9*time.Second))
+	e.AddExecution(at("BAZ", 11*time.Second))
+
+	// intervals of 2s, 1s and 6s
+	if d := e.ExpectedTimeToFill(TestStock); d != 3*time.Second {
+		t.Errorf("expected 3s, got %s", d)
+	}
+	if d := e.ExpectedTimeToFill("BAZ"); d != 10*time.Second {
+		t.Errorf("expected 10s, got %s", d)
+	}
+}
@@ -2,10 +2,18 @@ package starfighter
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log"
+	"net"
 	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
 )
 
 const (
@@ -13,22 +21,253 @@ const (
 	AuthHeader = "X-Starfighter-Authorization"
 	// APILocation sets the Starfighter API Location
 	APILocation = "https://api.stockfighter.io/ob/api"
+	// DefaultPollInterval is used when a Client's PollInterval isn't set
+	DefaultPollInterval = 500 * time.Millisecond
+	// DefaultStatusRetries is used when a Client's StatusRetries isn't set
+	DefaultStatusRetries = 2
+	// DefaultStatusTimeout is used when a Client's StatusTimeout isn't set
+	DefaultStatusTimeout = 30 * time.Second
+	// DefaultFillWindow is used when a Client's FillWindow isn't set
+	DefaultFillWindow = time.Minute
+	// DefaultMaxResponseBytes is used when a Client's MaxResponseBytes isn't set
+	DefaultMaxResponseBytes = 32 << 20
 )
 
+var (
+	// ErrNoAccount is returned for orders with no account, when the client
+	// has no DefaultAccount either.
+	ErrNoAccount = errors.New("starfighter: no account given and no DefaultAccount set")
+	// ErrResponseTooLarge is returned when a response is over the client's
+	// MaxResponseBytes.
+	ErrResponseTooLarge = errors.New("starfighter: response too large")
+)
+
+// TokenSource hands out API tokens, for when they don't last forever.
+type TokenSource interface {
+	// Token returns a fresh token.
+	Token(ctx context.Context) (string, error)
+}
+
+// Logger is what the client logs to. *log.Logger is one.
+type Logger interface {
+	Printf(format string, v ...any)
+}
+
 // Client reflects a HTTP REST client to the Starfighter API.
 type Client struct {
 	// Your Starfighter API Token
 	Token string
+	// If set, where a new Token comes from when the API says the one we
+	// have is no good (a 401). The request is then tried again, once.
+	TokenSource TokenSource
 	// Location of the API
 	Location string
 	// The HTTP Client to use
 	Client http.Client
+	// If set, how long connecting to the API may take, however long the
+	// request as a whole is allowed. Only used by HTTP clients with no
+	// Transport of their own.
+	DialTimeout time.Duration
+	// If set, used instead of Client for placing and cancelling orders,
+	// e.g. to keep a warm connection and a short timeout just for them
+	OrderClient *http.Client
+	// If set, used instead of Client for everything else: quotes, books,
+	// order status and the like
+	ReadClient *http.Client
+	// Extra headers to send with every request, e.g. for a proxy
+	Headers http.Header
+	// How often helpers that watch for something poll for it (default 500ms)
+	PollInterval time.Duration
+	// Retries for the order status and cancel calls, which are slow and
+	// time out a lot (default 2, negative for none)
+	StatusRetries int
+	// Timeout for each order status or cancel attempt (default 30s)
+	StatusTimeout time.Duration
+	// How the delays between those retries are randomized (default none)
+	StatusJitter Jitter
+	// If set, API errors it returns true for aren't treated as errors,
+	// e.g. ones that are just the API saying there's nothing there
+	IsBenignError func(*APIError) bool
+	// The account orders are placed for when they don't say
+	DefaultAccount string
+	// Cash the account starts the level with, in cents, for AccountBalance
+	StartingCapital int64
+	// Where the client logs to (default the standard logger)
+	Logger Logger
+	// If set, every raw websocket frame is logged before it's decoded
+	DebugFeeds bool
+	// How far back FillProbability looks at trading, and how far ahead it
+	// guesses (default 1 minute)
+	FillWindow time.Duration
+	// The biggest response body the client will read, so a broken API
+	// can't eat all your memory (default 32MB, negative for no limit)
+	MaxResponseBytes int64
+	// If set, helpers that call several venues at once give up on the rest
+	// as soon as one fails, rather than collecting every venue's error
+	FailFast bool
+
+	// guards the client's state below
+	mu sync.Mutex
+	// known StockInfo, by venue and stock
+	stockInfo map[string]StockInfo
+	// limits the rate of all requests, if set
+	limiter *rateLimiter
+	// limits the rate of orders, by symbol
+	symbolLimiters map[string]*rateLimiter
+	// the last orderbook seen, by venue and stock, if it can be revalidated
+	orderbooks map[string]*cachedOrderbook
+	// open orders placed through the client, by ID
+	orders map[int]OrderResultAlt
+	// shares held by symbol, from the fills of those orders
+	positions map[string]int64
+	// orders placed and filled so far
+	session SessionStats
+	// the most shares the client will let you hold, by symbol
+	positionLimits map[string]int64
+	// qty of orders being placed, held against the position limits
+	pendingQty map[positionSide]int64
+	// open websocket feeds
+	feeds map[*trackedFeed]struct{}
+	// what scheduled helpers tell the time by, if not the real clock
+	timeSource clock
+	// recent trades seen in quotes, by venue and stock
+	tape map[string][]tapeTrade
+	// when recent requests were sent, for RequestRate
+	requests []time.Time
+	// response time monitors, by endpoint pattern
+	slas map[string]*slaMonitor
+	// the transport used for DialTimeout, and the timeout it was made for
+	dialTransport        *http.Transport
+	dialTransportTimeout time.Duration
+	// dials for that transport, if not a net.Dialer
+	dial func(ctx context.Context, network, addr string) (net.Conn, error)
+}
+
+// logf logs to the client's Logger, or the standard logger without one.
+func (c *Client) logf(format string, v ...any) {
+	if c.Logger != nil {
+		c.Logger.Printf(format, v...)
+		return
+	}
+	log.Printf(format, v...)
 }
 
 // CallReq sets the authorization header and runs the request
 func (c *Client) CallReq(req *http.Request) (*http.Response, error) {
-	req.Header.Add(AuthHeader, c.Token)
-	return c.Client.Do(req)
+	return c.do(c.httpClient(req.Method), req)
+}
+
+// httpClient is the HTTP client for requests of the method: OrderClient
+// for the ones that place or cancel orders, ReadClient for the rest, and
+// Client for either that isn't set.
+func (c *Client) httpClient(method string) *http.Client {
+	switch {
+	case (method == "POST" || method == "DELETE") && c.OrderClient != nil:
+		return c.OrderClient
+	case method != "POST" && method != "DELETE" && c.ReadClient != nil:
+		return c.ReadClient
+	}
+	return &c.Client
+}
+
+// do is CallReq with a choice of HTTP client.
+func (c *Client) do(client *http.Client, req *http.Request) (*http.Response, error) {
+	if err := c.waitRateLimit(req.Context()); err != nil {
+		return nil, err
+	}
+
+	c.setHeaders(req)
+	resp, err := c.roundTrip(client, req)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized || c.TokenSource == nil {
+		return resp, err
+	}
+	if req.Body != nil && req.GetBody == nil {
+		// can't send it again
+		return resp, nil
+	}
+	resp.Body.Close()
+
+	return c.retryWithNewToken(client, req)
+}
+
+// retryWithNewToken gets a new token from the TokenSource and sends req
+// again with it.
+func (c *Client) retryWithNewToken(client *http.Client, req *http.Request) (*http.Response, error) {
+	token, err := c.TokenSource.Token(req.Context())
+	if err != nil {
+		return nil, fmt.Errorf("starfighter: getting a new token: %w", err)
+	}
+
+	c.mu.Lock()
+	c.Token = token
+	c.mu.Unlock()
+
+	retry := req.Clone(req.Context())
+	if req.GetBody != nil {
+		if retry.Body, err = req.GetBody(); err != nil {
+			return nil, err
+		}
+	}
+	retry.Header.Set(AuthHeader, token)
+
+	if err := c.waitRateLimit(retry.Context()); err != nil {
+		return nil, err
+	}
+	return c.roundTrip(client, retry)
+}
+
+// roundTrip sends the request, counting it and timing it.
+func (c *Client) roundTrip(client *http.Client, req *http.Request) (*http.Response, error) {
+	c.countRequest()
+
+	start := time.Now()
+	resp, err := c.withDialTimeout(client).Do(req)
+	if err == nil {
+		c.recordLatency(req, time.Since(start))
+	}
+	return resp, err
+}
+
+// withDialTimeout is client, with a transport that has the DialTimeout if
+// the client has one and needs it.
+func (c *Client) withDialTimeout(client *http.Client) *http.Client {
+	if c.DialTimeout <= 0 || client.Transport != nil {
+		return client
+	}
+
+	c.mu.Lock()
+	if c.dialTransport == nil || c.dialTransportTimeout != c.DialTimeout {
+		dial, timeout := c.dial, c.DialTimeout
+		if dial == nil {
+			dial = (&net.Dialer{KeepAlive: 30 * time.Second}).DialContext
+		}
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+			ctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+			return dial(ctx, network, addr)
+		}
+		c.dialTransport, c.dialTransportTimeout = transport, c.DialTimeout
+	}
+	transport := c.dialTransport
+	c.mu.Unlock()
+
+	withTimeout := *client
+	withTimeout.Transport = transport
+	return &withTimeout
+}
+
+// setHeaders adds the extra headers, then the authorization header so that
+// it can't be overwritten by them.
+func (c *Client) setHeaders(req *http.Request) {
+	for name, values := range c.Headers {
+		for _, value := range values {
+			req.Header.Add(name, value)
+		}
+	}
+	c.mu.Lock()
+	req.Header.Set(AuthHeader, c.Token)
+	c.mu.Unlock()
 }
 
 // Call hits a method, endpoint (without the location), with specified data (if necessary).
@@ -36,58 +275,168 @@ func (c *Client) CallReq(req *http.Request) (*http.Response, error) {
 // If an error is returned and it is of type APIError, then the API has barfed on you.
 // If it is not of type APIError, then your client has barfed on you.
 func (c *Client) Call(method, endpoint string, data interface{}) (map[string]interface{}, *bytes.Buffer, error) {
-	// set up the request
+	return c.CallContext(context.Background(), method, endpoint, data)
+}
+
+// CallContext is Call, but gives up when ctx is done.
+func (c *Client) CallContext(ctx context.Context, method, endpoint string, data interface{}) (map[string]interface{}, *bytes.Buffer, error) {
+	return c.call(ctx, c.httpClient(method), method, endpoint, data)
+}
+
+// CallWithQuery is Call with query parameters, which are encoded and added
+// to the endpoint.
+func (c *Client) CallWithQuery(method, endpoint string, query url.Values, data interface{}) (map[string]interface{}, *bytes.Buffer, error) {
+	return c.CallWithQueryContext(context.Background(), method, endpoint, query, data)
+}
+
+// CallWithQueryContext is CallWithQuery, but gives up when ctx is done.
+func (c *Client) CallWithQueryContext(ctx context.Context, method, endpoint string, query url.Values, data interface{}) (map[string]interface{}, *bytes.Buffer, error) {
+	return c.call(ctx, c.httpClient(method), method, withQuery(endpoint, query), data)
+}
+
+// withQuery adds the query to the endpoint, which may have one already.
+func withQuery(endpoint string, query url.Values) string {
+	if len(query) == 0 {
+		return endpoint
+	}
+	if strings.Contains(endpoint, "?") {
+		return endpoint + "&" + query.Encode()
+	}
+	return endpoint + "?" + query.Encode()
+}
+
+// call is CallContext with a choice of HTTP client.
+func (c *Client) call(ctx context.Context, client *http.Client, method, endpoint string, data interface{}) (map[string]interface{}, *bytes.Buffer, error) {
+	req, err := c.newRequest(ctx, method, endpoint, data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	body, copy, _, err := c.send(client, req)
+	return body, copy, err
+}
+
+// newRequest sets up a request for the endpoint, with data as its JSON body.
+func (c *Client) newRequest(ctx context.Context, method, endpoint string, data interface{}) (*http.Request, error) {
 	req, err := http.NewRequest(method, c.Location+endpoint, nil)
-	if data == nil {
+	if data != nil {
 		buf := &bytes.Buffer{}
 		encoder := json.NewEncoder(buf)
 		if err = encoder.Encode(data); err != nil {
-			return nil, nil, err
+			return nil, err
 		}
 
 		req, err = http.NewRequest(method, c.Location+endpoint, buf)
 	}
 
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
+	return req.WithContext(ctx), nil
+}
+
+// send runs the request and reads the response the way Call does. The
+// response is handed back too (with its body already read and closed), for
+// anyone who wants the status or headers.
+func (c *Client) send(client *http.Client, req *http.Request) (map[string]interface{}, *bytes.Buffer, *http.Response, error) {
 	// call the request
-	resp, err := c.CallReq(req)
+	resp, err := c.do(client, req)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 
 	defer resp.Body.Close()
 
 	// keep a copy in case other methods do strange things
 	copy := &bytes.Buffer{}
-	reader := io.TeeReader(resp.Body, copy)
+	reader := io.TeeReader(c.limitBody(resp.Body), copy)
 
 	// unmarshal
 	body := map[string]interface{}{}
 	decoder := json.NewDecoder(reader)
-	err = decoder.Decode(body)
-	if err != nil {
-		return nil, copy, err
+	err = decoder.Decode(&body)
+	if err != nil && err != io.EOF {
+		return nil, copy, resp, err
 	}
 
 	// and let's check for errors as a precaution
-	var apiErr error
-	if body["ok"] != false {
-		apiErr = &APIError{
-			Code:    resp.StatusCode,
-			Message: body["error"].(string),
+	var apiErr *APIError
+	if err == io.EOF {
+		// nothing came back (like a 204), which is fine unless it failed
+		if resp.StatusCode >= http.StatusBadRequest {
+			apiErr = newAPIError(resp.StatusCode, http.StatusText(resp.StatusCode))
 		}
+	} else if body["ok"] == false {
+		message, _ := body["error"].(string)
+		apiErr = newAPIError(resp.StatusCode, message)
+	}
+
+	if apiErr == nil || (c.IsBenignError != nil && c.IsBenignError(apiErr)) {
+		return body, copy, resp, nil
+	}
+
+	resumeAt, _ := body["resumeAt"].(string)
+	return body, copy, resp, maintenanceError(apiErr, resp, resumeAt)
+}
+
+// limitBody cuts body off at MaxResponseBytes, failing with
+// ErrResponseTooLarge if there's more.
+func (c *Client) limitBody(body io.Reader) io.Reader {
+	limit := c.MaxResponseBytes
+	if limit == 0 {
+		limit = DefaultMaxResponseBytes
+	}
+	if limit < 0 {
+		return body
+	}
+	return &limitedReader{r: io.LimitReader(body, limit+1), limit: limit}
+}
+
+// limitedReader reads up to limit bytes from r, which should be limited
+// to one more than that so we can tell if there was too much.
+type limitedReader struct {
+	r     io.Reader
+	limit int64
+	read  int64
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	n, err := l.r.Read(p)
+	if l.read += int64(n); l.read > l.limit {
+		return n - int(l.read-l.limit), fmt.Errorf("%w: more than %d bytes", ErrResponseTooLarge, l.limit)
 	}
+	return n, err
+}
 
-	return body, copy, apiErr
+func (c *Client) pollInterval() time.Duration {
+	if c.PollInterval <= 0 {
+		return DefaultPollInterval
+	}
+	return c.PollInterval
+}
+
+// decodeCopy decodes the copy of a response kept by Call into v.
+// An empty response (like a 204) leaves v as it is.
+func decodeCopy(copy *bytes.Buffer, v interface{}) error {
+	if copy.Len() == 0 {
+		return nil
+	}
+	return json.NewDecoder(copy).Decode(v)
 }
 
 // Heartbeat checks if the API is up. Because maybe it isn't.
 func (c *Client) Heartbeat() bool {
-	_, _, err := c.Call("GET", "/heartbeat", nil)
-	return err == nil
+	ok, _ := c.HeartbeatDetailed()
+	return ok
+}
+
+// HeartbeatDetailed is Heartbeat, but says why the API is down: the error
+// is whatever the request failed with, e.g. a *url.Error for a connection
+// that couldn't be made or an *APIError for the API saying no.
+func (c *Client) HeartbeatDetailed() (ok bool, err error) {
+	_, _, err = c.Call("GET", "/heartbeat", nil)
+	return err == nil, err
 }
 
 // VenueHealthCheck checks if a venue is up.
@@ -98,134 +447,240 @@ func (c *Client) VenueHealthCheck(venue string) bool {
 
 // ListVenueStocks lists the stocks in a venue
 func (c *Client) ListVenueStocks(venue string) ([]Stock, error) {
-	resp, _, err := c.Call("GET", fmt.Sprintf("/venues/%s/stocks", venue), nil)
+	return c.listVenueStocks(context.Background(), venue)
+}
+
+func (c *Client) listVenueStocks(ctx context.Context, venue string) ([]Stock, error) {
+	_, copy, err := c.CallContext(ctx, "GET", fmt.Sprintf("/venues/%s/stocks", venue), nil)
 	if err != nil {
 		return nil, err
 	}
 
-	stocks := make([]Stock, len(resp["symbols"].([]interface{})))
+	stockList := struct {
+		Symbols []Stock `json:"symbols"`
+	}{}
 
-	for k, v := range resp["symbols"].([]map[string]interface{}) {
-		stocks[k] = Stock{
-			Name:   v["name"].(string),
-			Symbol: v["symbol"].(string),
-		}
-	}
+	err = decodeCopy(copy, &stockList)
 
-	return stocks, nil
+	return stockList.Symbols, err
 }
 
 // GetStockOrderbook retrieves the orderbook for the stock requested.
 func (c *Client) GetStockOrderbook(venue, stock string) (*OrderBook, error) {
-	_, copy, err := c.Call("GET", fmt.Sprintf("/venues/%s/stocks/%s", venue, stock), nil)
+	return c.getStockOrderbook(context.Background(), venue, stock)
+}
+
+func (c *Client) getStockOrderbook(ctx context.Context, venue, stock string) (*OrderBook, error) {
+	req, err := c.newRequest(ctx, "GET", fmt.Sprintf("/venues/%s/stocks/%s", venue, stock), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// ask for it only if it's changed since we last saw it
+	cached := c.cachedOrderbook(venue, stock)
+	cached.setValidators(req)
+
+	_, copy, resp, err := c.send(c.httpClient(req.Method), req)
 	if err != nil {
 		return nil, err
 	}
 
+	if resp.StatusCode == http.StatusNotModified && cached != nil {
+		return cached.Book(), nil
+	}
+
 	orderBook := OrderBook{}
 
-	decoder := json.NewDecoder(copy)
-	err = decoder.Decode(&orderBook)
+	err = decodeCopy(copy, &orderBook)
+	if err == nil {
+		c.cacheOrderbook(venue, stock, resp, &orderBook)
+	}
 
 	return &orderBook, err
 }
 
-// PlaceStockOrder places an order for a stock.
+// PlaceStockOrder places an order for a stock. If account is empty, the
+// client's DefaultAccount is used.
 func (c *Client) PlaceStockOrder(account, venue, stock string, price int64, qty int64, direction, ordertype string) (*OrderResult, error) {
-	_, copy, err := c.Call("POST", fmt.Sprintf("/venues/%s/stocks/%s/orders", venue, stock), map[string]interface{}{
-		"account":   account,
-		"venue":     venue,
-		"stock":     stock,
-		"price":     price,
-		"qty":       qty,
-		"direction": direction,
-		"orderType": ordertype,
-	})
+	return c.placeStockOrder(context.Background(), account, venue, stock, price, qty, direction, ordertype)
+}
+
+// PlaceOrder is PlaceStockOrder for the client's DefaultAccount.
+func (c *Client) PlaceOrder(venue, stock string, price int64, qty int64, direction, ordertype string) (*OrderResult, error) {
+	return c.placeStockOrder(context.Background(), "", venue, stock, price, qty, direction, ordertype)
+}
+
+// orderRequest is the body of an order. It's a struct rather than a map so
+// the fields always go out in the same order, byte for byte.
+type orderRequest struct {
+	Account   string `json:"account"`
+	Venue     string `json:"venue"`
+	Stock     string `json:"stock"`
+	Price     int64  `json:"price"`
+	Qty       int64  `json:"qty"`
+	Direction string `json:"direction"`
+	OrderType string `json:"orderType"`
+}
 
+func (c *Client) placeStockOrder(ctx context.Context, account, venue, stock string, price int64, qty int64, direction, ordertype string) (*OrderResult, error) {
+	if account == "" {
+		if account = c.DefaultAccount; account == "" {
+			return nil, ErrNoAccount
+		}
+	}
+	if err := c.ValidateOrder(venue, stock, price, qty); err != nil {
+		return nil, err
+	}
+	release, err := c.reservePosition(stock, qty, direction)
 	if err != nil {
 		return nil, err
 	}
+	defer release()
+
+	if err := c.waitSymbolRateLimit(ctx, stock); err != nil {
+		return nil, err
+	}
+
+	_, copy, err := c.CallContext(ctx, "POST", fmt.Sprintf("/venues/%s/stocks/%s/orders", venue, stock), &orderRequest{
+		Account:   account,
+		Venue:     venue,
+		Stock:     stock,
+		Price:     price,
+		Qty:       qty,
+		Direction: direction,
+		OrderType: ordertype,
+	})
+
+	if err != nil {
+		return nil, orderError(err)
+	}
 
 	orderResult := OrderResult{}
 
-	decoder := json.NewDecoder(copy)
-	err = decoder.Decode(&orderResult)
+	if err = decodeCopy(copy, &orderResult); err != nil {
+		return &orderResult, err
+	}
+
+	tracked := OrderResultAlt(orderResult)
+	if tracked.Venue == "" {
+		tracked.Venue, tracked.Symbol = venue, stock
+	}
+	c.trackOrder(tracked, true)
 
-	return &orderResult, err
+	return &orderResult, nil
 }
 
 // QuoteStock shows you the most recent information. Which is probably outdated
 // by the time you actually interpret it. So why are you even doing this?
 func (c *Client) QuoteStock(venue, stock string) (*StockQuote, error) {
-	_, copy, err := c.Call("GET", fmt.Sprintf("/venues/%s/stocks/%s/quote", venue, stock), nil)
+	return c.quoteStock(context.Background(), venue, stock)
+}
+
+func (c *Client) quoteStock(ctx context.Context, venue, stock string) (*StockQuote, error) {
+	_, copy, err := c.CallContext(ctx, "GET", fmt.Sprintf("/venues/%s/stocks/%s/quote", venue, stock), nil)
 	if err != nil {
 		return nil, err
 	}
 
 	stockQuote := StockQuote{}
 
-	decoder := json.NewDecoder(copy)
-	err = decoder.Decode(&stockQuote)
+	if err = decodeCopy(copy, &stockQuote); err != nil {
+		return &stockQuote, err
+	}
+	c.recordTrade(venue, stock, &stockQuote)
 
-	return &stockQuote, err
+	return &stockQuote, nil
 }
 
 // GetOrderStatus retrieves the status for an existing order. Slowly.
 func (c *Client) GetOrderStatus(venue, stock string, order int64) (*OrderResultAlt, error) {
-	_, copy, err := c.Call("GET", fmt.Sprintf("/venues/%s/stocks/%s/orders/%d", venue, stock, order), nil)
+	return c.getOrderStatus(context.Background(), venue, stock, order)
+}
+
+func (c *Client) getOrderStatus(ctx context.Context, venue, stock string, order int64) (*OrderResultAlt, error) {
+	copy, err := c.callOrderStatus(ctx, "GET", fmt.Sprintf("/venues/%s/stocks/%s/orders/%d", venue, stock, order))
 	if err != nil {
 		return nil, err
 	}
 
 	orderResult := OrderResultAlt{}
 
-	decoder := json.NewDecoder(copy)
-	err = decoder.Decode(&orderResult)
+	if err = decodeCopy(copy, &orderResult); err != nil {
+		return &orderResult, err
+	}
+	if orderResult.ID != 0 {
+		c.trackOrder(orderResult, false)
+	}
 
-	return &orderResult, err
+	return &orderResult, nil
 }
 
 // CancelOrder attempts to cancel the order. Good luck, though.
 func (c *Client) CancelOrder(venue, stock string, order int64) (*OrderResultAlt, error) {
-	_, copy, err := c.Call("DELETE", fmt.Sprintf("/venues/%s/stocks/%s/orders/%d", venue, stock, order), nil)
+	return c.cancelOrder(context.Background(), venue, stock, order)
+}
+
+func (c *Client) cancelOrder(ctx context.Context, venue, stock string, order int64) (*OrderResultAlt, error) {
+	copy, err := c.callOrderStatus(ctx, "DELETE", fmt.Sprintf("/venues/%s/stocks/%s/orders/%d", venue, stock, order))
 	if err != nil {
 		return nil, err
 	}
 
 	orderResult := OrderResultAlt{}
 
-	decoder := json.NewDecoder(copy)
-	err = decoder.Decode(&orderResult)
+	if err = decodeCopy(copy, &orderResult); err != nil {
+		return &orderResult, err
+	}
+
+	// nothing back means there's nothing left to cancel
+	if orderResult.ID == 0 {
+		c.untrackOrder(int(order))
+	} else {
+		c.trackOrder(orderResult, false)
+	}
 
-	return &orderResult, err
+	return &orderResult, nil
 }
 
 // ListVenueOrderStatus lists the status of all orders for the venue and account.
+// Any the API sends for some other account are left out, with a warning.
 func (c *Client) ListVenueOrderStatus(venue, account string) (*OrderResultList, error) {
-	_, copy, err := c.Call("GET", fmt.Sprintf("/venues/%s/accounts/%s/orders", venue, account), nil)
+	return c.listVenueOrderStatus(context.Background(), venue, account)
+}
+
+func (c *Client) listVenueOrderStatus(ctx context.Context, venue, account string) (*OrderResultList, error) {
+	_, copy, err := c.CallContext(ctx, "GET", fmt.Sprintf("/venues/%s/accounts/%s/orders", venue, account), nil)
 	if err != nil {
 		return nil, err
 	}
 
 	orderResultList := OrderResultList{}
 
-	decoder := json.NewDecoder(copy)
-	err = decoder.Decode(&orderResultList)
+	if err = decodeCopy(copy, &orderResultList); err != nil {
+		return &orderResultList, err
+	}
+	orderResultList.dropOtherAccounts(account)
 
-	return &orderResultList, err
+	return &orderResultList, nil
 }
 
 // ListVenueStockOrderStatus lists the status of all orders for the venue, stock, and account.
 func (c *Client) ListVenueStockOrderStatus(venue, stock, account string) (*OrderResultList, error) {
-	_, copy, err := c.Call("GET", fmt.Sprintf("/venues/%s/accounts/%s/stocks/%s/orders", venue, stock, account), nil)
+	return c.listVenueStockOrderStatus(context.Background(), venue, stock, account)
+}
+
+func (c *Client) listVenueStockOrderStatus(ctx context.Context, venue, stock, account string) (*OrderResultList, error) {
+	_, copy, err := c.CallContext(ctx, "GET", fmt.Sprintf("/venues/%s/accounts/%s/stocks/%s/orders", venue, account, stock), nil)
 	if err != nil {
 		return nil, err
 	}
 
 	orderResultList := OrderResultList{}
 
-	decoder := json.NewDecoder(copy)
-	err = decoder.Decode(&orderResultList)
+	if err = decodeCopy(copy, &orderResultList); err != nil {
+		return &orderResultList, err
+	}
+	orderResultList.dropOtherAccounts(account)
 
-	return &orderResultList, err
+	return &orderResultList, nil
 }
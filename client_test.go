@@ -1,5 +1,21 @@
 package starfighter
 
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
 const (
 	// TestExchange will always be an available test exchange.
 	TestExchange = "TESTEX"
@@ -10,3 +26,384 @@ const (
 	// TestAccount will always be an available test account.
 	TestAccount = "EXB123456"
 )
+
+// newTestClient returns a client pointed at a test server running handler.
+func newTestClient(handler http.HandlerFunc) (*Client, *httptest.Server) {
+	server := httptest.NewServer(handler)
+	return &Client{Location: server.URL}, server
+}
+
+func TestCustomHeaders(t *testing.T) {
+	var headers http.Header
+	c, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		headers = r.Header
+		w.Write([]byte(`{"ok": true}`))
+	})
+	defer server.Close()
+
+	c.Token = "secret"
+	c.Headers = http.Header{
+		"X-Trace-Id": {"abc123"},
+		AuthHeader:   {"not-the-token"},
+	}
+
+	if !c.Heartbeat() {
+		t.Fatal("heartbeat failed")
+	}
+
+	if headers.Get("X-Trace-Id") != "abc123" {
+		t.Errorf("expected custom header, got %v", headers)
+	}
+	if values := headers[AuthHeader]; len(values) != 1 || values[0] != "secret" {
+		t.Errorf("expected only the token in the auth header, got %v", values)
+	}
+}
+
+// rotatingTokens hands out tokens in turn.
+type rotatingTokens struct {
+	tokens []string
+	calls  int
+}
+
+func (r *rotatingTokens) Token(ctx context.Context) (string, error) {
+	token := r.tokens[r.calls%len(r.tokens)]
+	r.calls++
+	return token, nil
+}
+
+func TestTokenSource(t *testing.T) {
+	var bodies []string
+	c, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+
+		if r.Header.Get(AuthHeader) != "fresh" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"ok": false, "error": "Bad API key"}`)
+			return
+		}
+		fmt.Fprint(w, `{"ok": true, "id": 1, "open": true}`)
+	})
+	defer server.Close()
+
+	source := &rotatingTokens{tokens: []string{"fresh"}}
+	c.Token = "expired"
+	c.TokenSource = source
+
+	if _, err := c.PlaceStockOrder(TestAccount, TestExchange, TestStock, 100, 10, "buy", "limit"); err != nil {
+		t.Fatal(err)
+	}
+	if source.calls != 1 || c.Token != "fresh" {
+		t.Errorf("expected one new token, got %d calls and %q", source.calls, c.Token)
+	}
+	if len(bodies) != 2 || bodies[0] == "" || bodies[1] != bodies[0] {
+		t.Errorf("expected the order sent again as it was, got %q", bodies)
+	}
+
+	// a new token that's no good either isn't tried forever
+	source.tokens = []string{"stale"}
+	c.Token = "expired"
+	bodies = nil
+
+	var apiErr *APIError
+	if _, _, err := c.Call("GET", "/heartbeat", nil); !errors.As(err, &apiErr) || apiErr.Code != http.StatusUnauthorized {
+		t.Errorf("expected a 401, got %v", err)
+	}
+	if len(bodies) != 2 || source.calls != 2 {
+		t.Errorf("expected one retry, got %d requests and %d tokens", len(bodies), source.calls)
+	}
+}
+
+func TestHeartbeatDetailed(t *testing.T) {
+	c, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"ok": true, "error": ""}`))
+	})
+	if ok, err := c.HeartbeatDetailed(); !ok || err != nil {
+		t.Errorf("expected the API to be up, got (%v, %v)", ok, err)
+	}
+	server.Close()
+
+	// nobody's there any more
+	var urlErr *url.Error
+	if ok, err := c.HeartbeatDetailed(); ok || !errors.As(err, &urlErr) {
+		t.Errorf("expected a transport error, got (%v, %v)", ok, err)
+	}
+
+	c, server = newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte(`{"ok": false, "error": "Service unavailable"}`))
+	})
+	defer server.Close()
+
+	var apiErr *APIError
+	if ok, err := c.HeartbeatDetailed(); ok || !errors.As(err, &apiErr) || apiErr.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected a 503, got (%v, %v)", ok, err)
+	}
+	if c.Heartbeat() {
+		t.Error("expected Heartbeat to agree")
+	}
+}
+
+// countingTransport counts the requests going through it, by method.
+type countingTransport struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.mu.Lock()
+	t.counts[req.Method]++
+	t.mu.Unlock()
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestOrderAndReadClients(t *testing.T) {
+	c, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"ok": true, "id": 1, "open": true}`)
+	})
+	defer server.Close()
+
+	orders := &countingTransport{counts: map[string]int{}}
+	reads := &countingTransport{counts: map[string]int{}}
+	c.OrderClient = &http.Client{Transport: orders}
+	c.ReadClient = &http.Client{Transport: reads}
+
+	if _, err := c.PlaceStockOrder(TestAccount, TestExchange, TestStock, 100, 10, "buy", "limit"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.CancelOrder(TestExchange, TestStock, 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.QuoteStock(TestExchange, TestStock); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.GetOrderStatus(TestExchange, TestStock, 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.GetStockOrderbook(TestExchange, TestStock); err != nil {
+		t.Fatal(err)
+	}
+
+	if fmt.Sprint(orders.counts) != "map[DELETE:1 POST:1]" {
+		t.Errorf("expected the order client to place and cancel, got %v", orders.counts)
+	}
+	if fmt.Sprint(reads.counts) != "map[GET:3]" {
+		t.Errorf("expected the read client to do the rest, got %v", reads.counts)
+	}
+}
+
+func TestDialTimeout(t *testing.T) {
+	c, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"ok": true}`)
+	})
+	defer server.Close()
+	c.DialTimeout = 100 * time.Millisecond
+	c.Client.Timeout = 10 * time.Second
+
+	// a dial that never connects, like one to an address nothing answers on
+	c.dial = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+
+	start := time.Now()
+	if _, _, err := c.Call("GET", "/heartbeat", nil); err == nil {
+		t.Fatal("expected the connection to fail")
+	}
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond || elapsed > time.Second {
+		t.Errorf("expected to give up after the 100ms dial timeout, took %v", elapsed)
+	}
+	if c.Client.Transport != nil {
+		t.Error("expected the client's transport to be left alone")
+	}
+
+	// the transport is rebuilt when the timeout changes
+	c.dial = nil
+	c.DialTimeout = time.Second
+	if _, _, err := c.Call("GET", "/heartbeat", nil); err != nil {
+		t.Errorf("expected a real dial to connect, got %v", err)
+	}
+
+	// a transport of your own is left alone
+	c.Client.Transport = &countingTransport{}
+	if client := c.withDialTimeout(&c.Client); client.Transport != c.Client.Transport {
+		t.Error("expected the client's own transport to be used")
+	}
+}
+
+func TestMaxResponseBytes(t *testing.T) {
+	c, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"ok": true, "padding": %q}`, strings.Repeat("x", 1000))
+	})
+	defer server.Close()
+
+	if _, _, err := c.Call("GET", "/heartbeat", nil); err != nil {
+		t.Fatalf("expected the default limit to be plenty, got %v", err)
+	}
+
+	c.MaxResponseBytes = 100
+	if _, _, err := c.Call("GET", "/heartbeat", nil); !errors.Is(err, ErrResponseTooLarge) {
+		t.Errorf("expected ErrResponseTooLarge, got %v", err)
+	}
+	if _, err := c.ListVenueOrderStatusUnbuffered(context.Background(), TestExchange, TestAccount); !errors.Is(err, ErrResponseTooLarge) {
+		t.Errorf("expected ErrResponseTooLarge when streaming, got %v", err)
+	}
+
+	c.MaxResponseBytes = -1
+	if _, _, err := c.Call("GET", "/heartbeat", nil); err != nil {
+		t.Errorf("expected no limit, got %v", err)
+	}
+}
+
+func TestEmptyResponse(t *testing.T) {
+	c, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	defer server.Close()
+
+	body, _, err := c.Call("DELETE", "/venues/TESTEX/stocks/FOOBAR/orders/1", nil)
+	if err != nil || body == nil || len(body) != 0 {
+		t.Errorf("expected an empty body and no error, got %v, %v", body, err)
+	}
+
+	order, err := c.CancelOrder(TestExchange, TestStock, 1)
+	if err != nil || order == nil {
+		t.Errorf("expected an empty order and no error, got %+v, %v", order, err)
+	}
+}
+
+func TestEmptyErrorResponse(t *testing.T) {
+	c, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	defer server.Close()
+
+	_, err := c.QuoteStock(TestExchange, TestStock)
+	if apiErr, ok := err.(*APIError); !ok || apiErr.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected a 503 APIError, got %v", err)
+	}
+}
+
+func TestIsBenignError(t *testing.T) {
+	c, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"ok": false, "error": "No orders for that account"}`))
+	})
+	defer server.Close()
+
+	if _, err := c.ListVenueOrderStatus(TestExchange, TestAccount); err == nil {
+		t.Fatal("expected an error without a predicate")
+	}
+
+	c.IsBenignError = func(err *APIError) bool {
+		return err.Message == "No orders for that account"
+	}
+
+	body, _, err := c.Call("GET", "/venues/TESTEX/accounts/EXB123456/orders", nil)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if body["error"] != "No orders for that account" {
+		t.Errorf("expected the parsed body, got %v", body)
+	}
+
+	list, err := c.ListVenueOrderStatus(TestExchange, TestAccount)
+	if err != nil || len(list.Orders) != 0 {
+		t.Errorf("expected an empty list, got %+v, %v", list, err)
+	}
+}
+
+func TestPlaceStockOrderBody(t *testing.T) {
+	var bodies []string
+	c, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		w.Write([]byte(`{"ok": true}`))
+	})
+	defer server.Close()
+
+	for k := 0; k < 5; k++ {
+		if _, err := c.PlaceStockOrder(TestAccount, TestExchange, TestStock, 5000, 100, "buy", "limit"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expected := `{"account":"EXB123456","venue":"TESTEX","stock":"FOOBAR","price":5000,"qty":100,"direction":"buy","orderType":"limit"}` + "\n"
+	for k, body := range bodies {
+		if body != expected {
+			t.Errorf("call %d: expected body %q, got %q", k, expected, body)
+		}
+	}
+}
+
+func TestDefaultAccount(t *testing.T) {
+	var placed orderRequest
+	c, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		placed = orderRequest{}
+		json.NewDecoder(r.Body).Decode(&placed)
+		w.Write([]byte(`{"ok": true}`))
+	})
+	defer server.Close()
+
+	if _, err := c.PlaceOrder(TestExchange, TestStock, 100, 10, "buy", "limit"); err != ErrNoAccount {
+		t.Errorf("expected ErrNoAccount with no account at all, got %v", err)
+	}
+
+	c.DefaultAccount = "DEFAULT123"
+
+	if _, err := c.PlaceOrder(TestExchange, TestStock, 100, 10, "buy", "limit"); err != nil {
+		t.Fatal(err)
+	}
+	if placed.Account != "DEFAULT123" {
+		t.Errorf("expected the default account, got %q", placed.Account)
+	}
+
+	if _, err := c.PlaceStockOrder("", TestExchange, TestStock, 100, 10, "buy", "limit"); err != nil {
+		t.Fatal(err)
+	}
+	if placed.Account != "DEFAULT123" {
+		t.Errorf("expected the default account for an empty one, got %q", placed.Account)
+	}
+
+	if _, err := c.PlaceStockOrder(TestAccount, TestExchange, TestStock, 100, 10, "buy", "limit"); err != nil {
+		t.Fatal(err)
+	}
+	if placed.Account != TestAccount {
+		t.Errorf("expected %q to override the default, got %q", TestAccount, placed.Account)
+	}
+}
+
+func TestCallWithQuery(t *testing.T) {
+	var queries []string
+	c, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.RawQuery)
+		w.Write([]byte(`{"ok": true}`))
+	})
+	defer server.Close()
+
+	query := url.Values{
+		"since":  {"2016-01-01T12:00:00Z"},
+		"limit":  {"10"},
+		"symbol": {"FOO BAR&BAZ"},
+	}
+	if _, _, err := c.CallWithQuery("GET", "/venues/TESTEX/orders", query, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := c.CallWithQuery("GET", "/venues/TESTEX/orders?open=true", url.Values{"limit": {"5"}}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := c.CallWithQuery("GET", "/venues/TESTEX/orders", nil, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"limit=10&since=2016-01-01T12%3A00%3A00Z&symbol=FOO+BAR%26BAZ",
+		"open=true&limit=5",
+		"",
+	}
+	for k, query := range expected {
+		if queries[k] != query {
+			t.Errorf("call %d: expected query %q, got %q", k, query, queries[k])
+		}
+	}
+}
@@ -0,0 +1,23 @@
+package starfighter
+
+import "time"
+
+// clock tells the time, so that tests of things that schedule themselves
+// don't have to wait around.
+type clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// clock is the client's clock, which is the real one outside of tests.
+func (c *Client) clock() clock {
+	if c.timeSource == nil {
+		return realClock{}
+	}
+	return c.timeSource
+}
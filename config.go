@@ -0,0 +1,99 @@
+package starfighter
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"time"
+)
+
+const (
+	// TokenEnv is the environment variable NewClientFromEnv reads the token from
+	TokenEnv = "STARFIGHTER_API_KEY"
+	// LocationEnv is the environment variable NewClientFromEnv reads the location from
+	LocationEnv = "STARFIGHTER_API_LOCATION"
+)
+
+// ClientConfig is the JSON configuration read by LoadClientConfig.
+type ClientConfig struct {
+	// Your Starfighter API Token (required)
+	Token string `json:"token"`
+	// Location of the API (defaults to APILocation)
+	Location string `json:"location"`
+	// Request timeout, as a duration like "10s" (defaults to none)
+	Timeout string `json:"timeout"`
+	// Requests per second to limit the client to (defaults to no limit)
+	RateLimit float64 `json:"rateLimit"`
+	// Requests that may go over the limit at once (defaults to 1)
+	RateBurst int `json:"rateBurst"`
+}
+
+// LoadClientConfig reads a ClientConfig from r and builds a client with it,
+// so you can keep your token out of your code.
+func LoadClientConfig(r io.Reader) (*Client, error) {
+	config := ClientConfig{}
+	if err := json.NewDecoder(r).Decode(&config); err != nil {
+		return nil, fmt.Errorf("starfighter: reading config: %w", err)
+	}
+
+	if config.Token == "" {
+		return nil, errors.New("starfighter: config: token is required")
+	}
+
+	if config.Location == "" {
+		config.Location = APILocation
+	}
+	location, err := url.Parse(config.Location)
+	if err != nil || (location.Scheme != "http" && location.Scheme != "https") || location.Host == "" {
+		return nil, fmt.Errorf("starfighter: config: location %q is not a http(s) URL", config.Location)
+	}
+
+	timeout := time.Duration(0)
+	if config.Timeout != "" {
+		if timeout, err = time.ParseDuration(config.Timeout); err != nil || timeout < 0 {
+			return nil, fmt.Errorf("starfighter: config: timeout %q is not a valid duration", config.Timeout)
+		}
+	}
+
+	if config.RateLimit < 0 || config.RateBurst < 0 {
+		return nil, errors.New("starfighter: config: rateLimit and rateBurst can't be negative")
+	}
+
+	c := &Client{
+		Token:    config.Token,
+		Location: config.Location,
+	}
+	c.Client.Timeout = timeout
+	c.SetRateLimit(config.RateLimit, config.RateBurst)
+
+	return c, nil
+}
+
+// NewClientFromEnv builds a client with the token in TokenEnv and, if it's
+// set, the location in LocationEnv (otherwise APILocation).
+func NewClientFromEnv() (*Client, error) {
+	return NewClientFromNamedEnv(TokenEnv, LocationEnv)
+}
+
+// NewClientFromNamedEnv is NewClientFromEnv for environment variables
+// called something else: the token is read from tokenEnv and the location
+// from locationEnv.
+func NewClientFromNamedEnv(tokenEnv, locationEnv string) (*Client, error) {
+	token := os.Getenv(tokenEnv)
+	if token == "" {
+		return nil, fmt.Errorf("starfighter: %s is not set", tokenEnv)
+	}
+
+	location := os.Getenv(locationEnv)
+	if location == "" {
+		location = APILocation
+	}
+
+	return &Client{
+		Token:    token,
+		Location: location,
+	}, nil
+}
@@ -0,0 +1,106 @@
+package starfighter
+
+import (
+	"errors"
+	"io/fs"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+)
+
+func TestLoadClientConfig(t *testing.T) {
+	c, err := LoadClientConfig(strings.NewReader(`{
+		"token": "secret",
+		"location": "http://localhost:8080/ob/api",
+		"timeout": "5s",
+		"rateLimit": 10,
+		"rateBurst": 3
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if c.Token != "secret" || c.Location != "http://localhost:8080/ob/api" || c.Client.Timeout != 5*time.Second {
+		t.Errorf("unexpected client: %+v", c)
+	}
+	if c.limiter == nil || c.limiter.rate != 10 || c.limiter.burst != 3 {
+		t.Errorf("unexpected rate limit: %+v", c.limiter)
+	}
+}
+
+func TestLoadClientConfigDefaults(t *testing.T) {
+	c, err := LoadClientConfig(strings.NewReader(`{"token": "secret"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if c.Location != APILocation || c.Client.Timeout != 0 || c.limiter != nil {
+		t.Errorf("unexpected client: %+v", c)
+	}
+}
+
+func TestLoadClientConfigInvalid(t *testing.T) {
+	for _, config := range []string{
+		`{"location": "http://localhost"}`,
+		`{"token": "secret", "location": "localhost"}`,
+		`{"token": "secret", "timeout": "soon"}`,
+		`{"token": "secret", "rateLimit": -1}`,
+		`not json`,
+	} {
+		if _, err := LoadClientConfig(strings.NewReader(config)); err == nil {
+			t.Errorf("expected an error for %s", config)
+		}
+	}
+}
+
+func TestLoadClientConfigReadError(t *testing.T) {
+	if _, err := LoadClientConfig(iotest.ErrReader(fs.ErrNotExist)); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected the read error to be wrapped, got %v", err)
+	}
+}
+
+func TestNewClientFromEnv(t *testing.T) {
+	t.Setenv(TokenEnv, "secret")
+	t.Setenv(LocationEnv, "")
+
+	c, err := NewClientFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Token != "secret" || c.Location != APILocation {
+		t.Errorf("unexpected client: %+v", c)
+	}
+
+	t.Setenv(LocationEnv, "http://localhost:8080/ob/api")
+	if c, err = NewClientFromEnv(); err != nil || c.Location != "http://localhost:8080/ob/api" {
+		t.Errorf("expected the location from the environment, got %+v, %v", c, err)
+	}
+}
+
+func TestNewClientFromEnvMissingToken(t *testing.T) {
+	t.Setenv(TokenEnv, "")
+
+	_, err := NewClientFromEnv()
+	if err == nil || !strings.Contains(err.Error(), TokenEnv) {
+		t.Errorf("expected an error naming %s, got %v", TokenEnv, err)
+	}
+}
+
+func TestNewClientFromNamedEnv(t *testing.T) {
+	t.Setenv(TokenEnv, "")
+	t.Setenv("MY_TOKEN", "secret")
+	t.Setenv("MY_LOCATION", "http://localhost:8080/ob/api")
+
+	c, err := NewClientFromNamedEnv("MY_TOKEN", "MY_LOCATION")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Token != "secret" || c.Location != "http://localhost:8080/ob/api" {
+		t.Errorf("unexpected client: %+v", c)
+	}
+
+	if _, err = NewClientFromNamedEnv("MY_MISSING_TOKEN", "MY_LOCATION"); err == nil || !strings.Contains(err.Error(), "MY_MISSING_TOKEN") {
+		t.Errorf("expected an error naming MY_MISSING_TOKEN, got %v", err)
+	}
+}
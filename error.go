@@ -1,15 +1,226 @@
 package starfighter
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	insufficientCapitalPattern = regexp.MustCompile(`(?i)insufficient (capital|funds|cash)`)
+	capitalAmountPattern       = regexp.MustCompile(`\$([\d,]+)(?:\.(\d{1,2}))?|(\d+)\s*cents?`)
+	venueClosedPattern         = regexp.MustCompile(`(?i)(venue|market|exchange)\b.*\b(closed|not (open|running|up))`)
+	authPattern                = regexp.MustCompile(`(?i)(auth|api key|not permitted|not allowed to)`)
+	maintenancePattern         = regexp.MustCompile(`(?i)maintenance`)
+	notFoundPattern            = regexp.MustCompile(`(?i)(no \w+ exists|not found|no such|doesn't exist|does not exist|unknown (venue|stock|symbol|order))`)
+)
+
+// APIErrorCode says what sort of error the API returned, so you can switch
+// on it instead of on the message, which the API words however it likes.
+type APIErrorCode int
+
+const (
+	// ErrCodeUnknown is an error we can't place.
+	ErrCodeUnknown APIErrorCode = iota
+	// ErrCodeBadRequest is the API not liking what was asked for.
+	ErrCodeBadRequest
+	// ErrCodeAuth is a missing or bad API key, or a key that can't touch
+	// the account.
+	ErrCodeAuth
+	// ErrCodeNotFound is a venue, stock or order that isn't there.
+	ErrCodeNotFound
+	// ErrCodeVenueClosed is a venue that isn't trading right now.
+	ErrCodeVenueClosed
+	// ErrCodeRateLimited is being told to slow down.
+	ErrCodeRateLimited
+	// ErrCodeServer is the API falling over.
+	ErrCodeServer
+	// ErrCodeMaintenance is the API being down on purpose.
+	ErrCodeMaintenance
+)
+
+var apiErrorCodeNames = map[APIErrorCode]string{
+	ErrCodeUnknown:     "unknown",
+	ErrCodeBadRequest:  "bad request",
+	ErrCodeAuth:        "auth",
+	ErrCodeNotFound:    "not found",
+	ErrCodeVenueClosed: "venue closed",
+	ErrCodeRateLimited: "rate limited",
+	ErrCodeServer:      "server",
+	ErrCodeMaintenance: "maintenance",
+}
+
+func (c APIErrorCode) String() string {
+	if name, ok := apiErrorCodeNames[c]; ok {
+		return name
+	}
+	return fmt.Sprintf("APIErrorCode(%d)", int(c))
+}
 
 // APIError is for when the request processes, but returns ok = false.
 // The message set is the one returned in the JSON response.
 type APIError struct {
 	Code    int
 	Message string
+	// ErrorCode is what sort of error it is, worked out from the two above
+	ErrorCode APIErrorCode
+}
+
+// newAPIError makes an APIError, working out its ErrorCode.
+func newAPIError(code int, message string) *APIError {
+	return &APIError{
+		Code:      code,
+		Message:   message,
+		ErrorCode: classifyAPIError(code, message),
+	}
+}
+
+// classifyAPIError goes by the message first, since the API isn't too
+// careful with its statuses (a closed venue can come back as a 500 or a
+// 404), then by the status.
+func classifyAPIError(code int, message string) APIErrorCode {
+	switch {
+	case maintenancePattern.MatchString(message):
+		return ErrCodeMaintenance
+	case venueClosedPattern.MatchString(message):
+		return ErrCodeVenueClosed
+	case code == http.StatusUnauthorized || code == http.StatusForbidden || authPattern.MatchString(message):
+		return ErrCodeAuth
+	case code == http.StatusNotFound || notFoundPattern.MatchString(message):
+		return ErrCodeNotFound
+	case code == http.StatusTooManyRequests:
+		return ErrCodeRateLimited
+	case code >= http.StatusInternalServerError:
+		return ErrCodeServer
+	case code >= http.StatusBadRequest:
+		return ErrCodeBadRequest
+	}
+	return ErrCodeUnknown
 }
 
 // Error is the error string
 func (a *APIError) Error() string {
 	return fmt.Sprintf("starfighter api error (%d): %s", a.Code, a.Message)
 }
+
+// ErrMaintenance is what a MaintenanceError is, for errors.Is.
+var ErrMaintenance = errors.New("starfighter: api is down for maintenance")
+
+// MaintenanceError is for when the API says it's down for maintenance.
+// Rather than spinning on it, wait until ResumeAt, which is when the API
+// said to come back (if it said; otherwise it's zero).
+type MaintenanceError struct {
+	*APIError
+	ResumeAt time.Time
+}
+
+// Is makes errors.Is(err, ErrMaintenance) true.
+func (m *MaintenanceError) Is(target error) bool {
+	return target == ErrMaintenance
+}
+
+// Unwrap gives the APIError, so errors.As still finds it.
+func (m *MaintenanceError) Unwrap() error {
+	return m.APIError
+}
+
+// maintenanceError turns the error into a MaintenanceError if it is one
+// (it says so, or it's a 503 that says when to come back), finding the
+// resume time in the Retry-After header (in seconds or as a date) or the
+// resumeAt the body came with.
+func maintenanceError(apiErr *APIError, resp *http.Response, resumeAt string) error {
+	scheduled := resp.StatusCode == http.StatusServiceUnavailable && resp.Header.Get("Retry-After") != ""
+	if apiErr.ErrorCode != ErrCodeMaintenance && !scheduled {
+		return apiErr
+	}
+	apiErr.ErrorCode = ErrCodeMaintenance
+
+	m := &MaintenanceError{APIError: apiErr}
+	if after := resp.Header.Get("Retry-After"); after != "" {
+		if seconds, err := strconv.Atoi(after); err == nil {
+			m.ResumeAt = time.Now().Add(time.Duration(seconds) * time.Second)
+		} else if at, err := http.ParseTime(after); err == nil {
+			m.ResumeAt = at
+		}
+	}
+	if at, err := time.Parse(time.RFC3339, resumeAt); err == nil && m.ResumeAt.IsZero() {
+		m.ResumeAt = at
+	}
+
+	return m
+}
+
+// InsufficientCapitalError is for when an order is rejected because the
+// account can't pay for it. RequiredCents is the shortfall if the API said
+// what it was, or 0 if it didn't (the raw message is still in the APIError).
+type InsufficientCapitalError struct {
+	*APIError
+	RequiredCents int64
+}
+
+// orderError turns order rejections into something more specific, if it can.
+func orderError(err error) error {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || !insufficientCapitalPattern.MatchString(apiErr.Message) {
+		return err
+	}
+
+	required, _ := parseCents(apiErr.Message)
+	return &InsufficientCapitalError{
+		APIError:      apiErr,
+		RequiredCents: required,
+	}
+}
+
+// parseCents finds the first amount of money in the message, either written
+// as dollars ($1,234.50) or as cents (123450 cents).
+func parseCents(message string) (int64, bool) {
+	match := capitalAmountPattern.FindStringSubmatch(message)
+	if match == nil {
+		return 0, false
+	}
+
+	if match[3] != "" {
+		cents, err := strconv.ParseInt(match[3], 10, 64)
+		return cents, err == nil
+	}
+
+	dollars, err := strconv.ParseInt(strings.Replace(match[1], ",", "", -1), 10, 64)
+	if err != nil {
+		return 0, false
+	}
+
+	cents := int64(0)
+	if match[2] != "" {
+		cents, _ = strconv.ParseInt(match[2], 10, 64)
+		if len(match[2]) == 1 {
+			cents *= 10
+		}
+	}
+
+	return dollars*100 + cents, true
+}
+
+// VenueErrors is what went wrong, by venue, when calling several venues at once.
+type VenueErrors map[string]error
+
+// Error is the error string
+func (v VenueErrors) Error() string {
+	venues := make([]string, 0, len(v))
+	for venue := range v {
+		venues = append(venues, venue)
+	}
+	sort.Strings(venues)
+
+	messages := make([]string, len(venues))
+	for k, venue := range venues {
+		messages[k] = fmt.Sprintf("%s: %v", venue, v[venue])
+	}
+
+	return fmt.Sprintf("starfighter: %d venue(s) failed: %s", len(v), strings.Join(messages, "; "))
+}
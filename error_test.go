@@ -0,0 +1,158 @@
+package starfighter
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPlaceStockOrderInsufficientCapital(t *testing.T) {
+	c, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"ok": false, "error": "Insufficient capital: order requires $1,234.50 more"}`)
+	})
+	defer server.Close()
+
+	_, err := c.PlaceStockOrder(TestAccount, TestExchange, TestStock, 5000, 100, "buy", "limit")
+	capitalErr, ok := err.(*InsufficientCapitalError)
+	if !ok {
+		t.Fatalf("expected InsufficientCapitalError, got %v", err)
+	}
+
+	if capitalErr.RequiredCents != 123450 {
+		t.Errorf("expected 123450 cents required, got %d", capitalErr.RequiredCents)
+	}
+	if capitalErr.Code != http.StatusBadRequest {
+		t.Errorf("expected code 400, got %d", capitalErr.Code)
+	}
+}
+
+func TestOrderErrorUnparseableShortfall(t *testing.T) {
+	err := orderError(&APIError{Code: 400, Message: "insufficient funds"})
+
+	capitalErr, ok := err.(*InsufficientCapitalError)
+	if !ok {
+		t.Fatalf("expected InsufficientCapitalError, got %v", err)
+	}
+	if capitalErr.RequiredCents != 0 || capitalErr.Message != "insufficient funds" {
+		t.Errorf("unexpected error: %+v", capitalErr)
+	}
+}
+
+func TestOrderErrorOtherRejection(t *testing.T) {
+	apiErr := &APIError{Code: 400, Message: "bad direction"}
+	if err := orderError(apiErr); err != apiErr {
+		t.Errorf("expected the original error back, got %v", err)
+	}
+}
+
+func TestAPIErrorCode(t *testing.T) {
+	for _, c := range []struct {
+		status  int
+		message string
+		code    APIErrorCode
+	}{
+		{http.StatusInternalServerError, "Venue TESTEX is closed right now", ErrCodeVenueClosed},
+		{http.StatusNotFound, "The market is not open", ErrCodeVenueClosed},
+		{http.StatusUnauthorized, "Unauthorized", ErrCodeAuth},
+		{http.StatusOK, "Not authorized to trade on that account", ErrCodeAuth},
+		{http.StatusNotFound, "No venue exists with the symbol DOWNEX", ErrCodeNotFound},
+		{http.StatusOK, "No stock exists with the symbol NOPE", ErrCodeNotFound},
+		{http.StatusTooManyRequests, "Slow down", ErrCodeRateLimited},
+		{http.StatusBadGateway, "Bad Gateway", ErrCodeServer},
+		{http.StatusBadRequest, "Qty must be positive", ErrCodeBadRequest},
+		{http.StatusOK, "Something odd", ErrCodeUnknown},
+	} {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(c.status)
+			fmt.Fprintf(w, `{"ok": false, "error": %q}`, c.message)
+		}))
+		client := &Client{Location: server.URL}
+
+		_, _, err := client.Call("GET", "/heartbeat", nil)
+		server.Close()
+
+		apiErr, ok := err.(*APIError)
+		if !ok {
+			t.Errorf("%d %q: expected an APIError, got %v", c.status, c.message, err)
+			continue
+		}
+		if apiErr.ErrorCode != c.code {
+			t.Errorf("%d %q: expected %s, got %s", c.status, c.message, c.code, apiErr.ErrorCode)
+		}
+	}
+}
+
+func TestAPIErrorCodeString(t *testing.T) {
+	for code := ErrCodeUnknown; code <= ErrCodeMaintenance; code++ {
+		if name := code.String(); strings.HasPrefix(name, "APIErrorCode(") {
+			t.Errorf("expected a name for code %d, got %s", int(code), name)
+		}
+	}
+	if name := ErrCodeMaintenance.String(); name != "maintenance" {
+		t.Errorf("expected maintenance, got %s", name)
+	}
+	if name := APIErrorCode(99).String(); name != "APIErrorCode(99)" {
+		t.Errorf("expected APIErrorCode(99), got %s", name)
+	}
+}
+
+func TestMaintenanceError(t *testing.T) {
+	resume := time.Date(2016, 1, 1, 13, 0, 0, 0, time.UTC)
+
+	for _, c := range []struct {
+		name     string
+		header   string
+		body     string
+		resumeAt time.Time
+	}{
+		{"message", "", `{"ok": false, "error": "Down for scheduled maintenance"}`, time.Time{}},
+		{"body time", "", `{"ok": false, "error": "Maintenance in progress", "resumeAt": "2016-01-01T13:00:00Z"}`, resume},
+		{"header date", resume.Format(http.TimeFormat), ``, resume},
+	} {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if c.header != "" {
+				w.Header().Set("Retry-After", c.header)
+			}
+			w.WriteHeader(http.StatusServiceUnavailable)
+			fmt.Fprint(w, c.body)
+		}))
+		client := &Client{Location: server.URL}
+
+		_, _, err := client.Call("GET", "/heartbeat", nil)
+		server.Close()
+
+		if !errors.Is(err, ErrMaintenance) {
+			t.Errorf("%s: expected ErrMaintenance, got %v", c.name, err)
+			continue
+		}
+		m := err.(*MaintenanceError)
+		if !m.ResumeAt.Equal(c.resumeAt) || m.ErrorCode != ErrCodeMaintenance || m.Code != http.StatusServiceUnavailable {
+			t.Errorf("%s: unexpected %+v (resume at %s)", c.name, m.APIError, m.ResumeAt)
+		}
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) || apiErr != m.APIError {
+			t.Errorf("%s: expected errors.As to find the APIError, got %v", c.name, apiErr)
+		}
+	}
+
+	// seconds from now
+	c, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "120")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	defer server.Close()
+
+	_, _, err := c.Call("GET", "/heartbeat", nil)
+	m, ok := err.(*MaintenanceError)
+	if !ok {
+		t.Fatalf("expected a MaintenanceError, got %v", err)
+	}
+	if wait := time.Until(m.ResumeAt); wait < 115*time.Second || wait > 120*time.Second {
+		t.Errorf("expected to resume in about 2 minutes, got %s", wait)
+	}
+}
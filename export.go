@@ -0,0 +1,50 @@
+package starfighter
+
+import (
+	"context"
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+)
+
+// ExportExecutions writes the account's fills on the venue to w as CSV, a
+// row at a time as they come in from the executions feed, until ctx is
+// cancelled. The first row is a header. Cancelling ctx is the normal way
+// to stop, so it's not an error; the feed failing or w failing is.
+func (c *Client) ExportExecutions(ctx context.Context, account, venue string, w io.Writer) error {
+	// stop the feed if we give up early
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	executions, errs, err := c.SubscribeExecutions(ctx, account, venue)
+	if err != nil {
+		return err
+	}
+
+	out := csv.NewWriter(w)
+	write := func(record ...string) error {
+		out.Write(record)
+		out.Flush()
+		return out.Error()
+	}
+
+	if err := write("timestamp", "symbol", "side", "price", "qty"); err != nil {
+		return err
+	}
+
+	for execution := range executions {
+		err := write(
+			execution.FilledAt.Format(time.RFC3339Nano),
+			execution.Symbol,
+			string(execution.Order.Direction),
+			strconv.Itoa(execution.Price),
+			strconv.Itoa(execution.Filled),
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return <-errs
+}
@@ -0,0 +1,51 @@
+package starfighter
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+)
+
+// cancelAfter cancels once it's been written to n times.
+type cancelAfter struct {
+	bytes.Buffer
+	n      int
+	cancel context.CancelFunc
+}
+
+func (w *cancelAfter) Write(p []byte) (int, error) {
+	if w.n--; w.n == 0 {
+		defer w.cancel()
+	}
+	return w.Buffer.Write(p)
+}
+
+func executionFrame(symbol, direction string, price, qty int, at string) string {
+	return fmt.Sprintf(`{"ok": true, "account": %q, "venue": %q, "symbol": %q, "order": {"direction": %q}, "price": %d, "filled": %d, "filledAt": %q}`,
+		TestAccount, TestExchange, symbol, direction, price, qty, at)
+}
+
+func TestExportExecutions(t *testing.T) {
+	frames := []string{
+		executionFrame(TestStock, "buy", 5000, 10, "2016-01-01T12:00:00Z"),
+		executionFrame("BAZ", "sell", 4990, 5, "2016-01-01T12:00:01.5Z"),
+	}
+	c, server := newFeedServer(t, nil, frames, nil, nil)
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	out := &cancelAfter{n: 3, cancel: cancel}
+	if err := c.ExportExecutions(ctx, TestAccount, TestExchange, out); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "timestamp,symbol,side,price,qty\n" +
+		"2016-01-01T12:00:00Z,FOOBAR,buy,5000,10\n" +
+		"2016-01-01T12:00:01.5Z,BAZ,sell,4990,5\n"
+	if out.String() != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, out.String())
+	}
+}
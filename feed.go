@@ -0,0 +1,147 @@
+package starfighter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// SubscribeQuotes streams quotes for every stock on the venue from the
+// tickertape. The quote channel is closed when the feed ends, which it does
+// when ctx is done (cancelled or past its deadline) or the connection drops;
+// in the latter case the error channel gets the reason first.
+func (c *Client) SubscribeQuotes(ctx context.Context, account, venue string) (<-chan StockQuote, <-chan error, error) {
+	return c.subscribeQuotes(ctx, account, venue, nil)
+}
+
+// SubscribeWatchlist is SubscribeQuotes, but only passes on quotes for the
+// given symbols.
+func (c *Client) SubscribeWatchlist(ctx context.Context, account, venue string, symbols []string) (<-chan StockQuote, <-chan error, error) {
+	watchlist := make(map[string]struct{}, len(symbols))
+	for _, symbol := range symbols {
+		watchlist[symbol] = struct{}{}
+	}
+
+	return c.subscribeQuotes(ctx, account, venue, func(quote *StockQuote) bool {
+		_, ok := watchlist[quote.Symbol]
+		return ok
+	})
+}
+
+// subscribeQuotes subscribes to the tickertape, passing on the quotes that
+// keep is true of (or all of them, if it's nil).
+func (c *Client) subscribeQuotes(ctx context.Context, account, venue string, keep func(*StockQuote) bool) (<-chan StockQuote, <-chan error, error) {
+	quotes := make(chan StockQuote)
+
+	errs, err := c.feed(ctx, fmt.Sprintf("/ws/%s/venues/%s/tickertape", account, venue), func(ctx context.Context, frame []byte) error {
+		msg := struct {
+			Quote StockQuote `json:"quote"`
+		}{}
+		if err := json.Unmarshal(frame, &msg); err != nil {
+			return err
+		}
+
+		if keep != nil && !keep(&msg.Quote) {
+			return nil
+		}
+
+		select {
+		case quotes <- msg.Quote:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}, func() {
+		close(quotes)
+	})
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return quotes, errs, nil
+}
+
+// SubscribeExecutions streams fills of the account's orders on the venue.
+// The channels behave as they do for SubscribeQuotes.
+func (c *Client) SubscribeExecutions(ctx context.Context, account, venue string) (<-chan Execution, <-chan error, error) {
+	executions := make(chan Execution)
+
+	errs, err := c.feed(ctx, fmt.Sprintf("/ws/%s/venues/%s/executions", account, venue), func(ctx context.Context, frame []byte) error {
+		execution := Execution{}
+		if err := json.Unmarshal(frame, &execution); err != nil {
+			return err
+		}
+
+		select {
+		case executions <- execution:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}, func() {
+		close(executions)
+	})
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return executions, errs, nil
+}
+
+// feed connects to the websocket endpoint and hands each message to handle
+// (logging it first if DebugFeeds is set) until ctx is done, the connection
+// drops, handle fails or the client is shut down. handle gets the feed's
+// own ctx, which is done in any of those cases. finish runs once it's over.
+// The error channel gets whatever ended the feed, unless it was ctx, and is
+// then closed.
+func (c *Client) feed(ctx context.Context, endpoint string, handle func(context.Context, []byte) error, finish func()) (<-chan error, error) {
+	ctx, untrack := c.trackFeed(ctx)
+
+	ws, err := c.dialFeed(ctx, endpoint)
+	if err != nil {
+		untrack()
+		return nil, err
+	}
+
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(errs)
+		defer untrack()
+		defer finish()
+		defer ws.Close()
+
+		// unblock the read below when we're told to stop
+		done := make(chan struct{})
+		defer close(done)
+		go func() {
+			select {
+			case <-ctx.Done():
+				ws.Close()
+			case <-done:
+			}
+		}()
+
+		for {
+			frame, err := ws.ReadMessage()
+			if err == nil {
+				if c.DebugFeeds {
+					c.logf("starfighter: %s: %s", endpoint, frame)
+				}
+				err = handle(ctx, frame)
+			}
+
+			if err != nil {
+				if ctx.Err() == nil && err != io.EOF {
+					errs <- err
+				}
+				return
+			}
+		}
+	}()
+
+	return errs, nil
+}
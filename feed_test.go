@@ -0,0 +1,288 @@
+package starfighter
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newFeedServer serves websocket connections that wait for start to be
+// closed, send frames and then hold the connection open until the client
+// goes away. Every connection is counted on conns. Anything that isn't a
+// websocket goes to fallback, if there is one.
+func newFeedServer(t *testing.T, start <-chan struct{}, frames []string, conns chan<- string, fallback http.HandlerFunc) (*Client, *httptest.Server) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Upgrade") != "websocket" {
+			if fallback != nil {
+				fallback(w, r)
+				return
+			}
+			http.Error(w, `{"ok": false, "error": "not a websocket"}`, http.StatusBadRequest)
+			return
+		}
+		if conns != nil {
+			conns <- r.URL.Path
+		}
+
+		conn, rw, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+
+		fmt.Fprintf(rw, "HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Accept: %s\r\n\r\n",
+			wsAccept(r.Header.Get("Sec-WebSocket-Key")))
+		rw.Flush()
+
+		if start != nil {
+			<-start
+		}
+		for _, frame := range frames {
+			writeFrame(rw, wsText, []byte(frame), false)
+		}
+		rw.Flush()
+
+		// wait for the client to hang up
+		io.Copy(io.Discard, bufio.NewReader(conn))
+	}))
+
+	return &Client{Location: server.URL}, server
+}
+
+func quoteFrame(symbol string, last int) string {
+	return fmt.Sprintf(`{"ok": true, "quote": {"symbol": %q, "venue": %q, "last": %d}}`, symbol, TestExchange, last)
+}
+
+func TestSubscribeQuotes(t *testing.T) {
+	c, server := newFeedServer(t, nil, []string{quoteFrame(TestStock, 100), quoteFrame(TestStock, 101)}, nil, nil)
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	quotes, errs, err := c.SubscribeQuotes(ctx, TestAccount, TestExchange)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, last := range []int{100, 101} {
+		quote := <-quotes
+		if quote.Last != last {
+			t.Errorf("expected last %d, got %d", last, quote.Last)
+		}
+	}
+
+	cancel()
+	if _, ok := <-quotes; ok {
+		t.Error("expected quotes to close after cancel")
+	}
+	if err, ok := <-errs; ok {
+		t.Errorf("expected no error after cancel, got %v", err)
+	}
+}
+
+// captureLogger keeps everything logged to it.
+type captureLogger struct {
+	mu    sync.Mutex
+	lines []string
+}
+
+func (l *captureLogger) Printf(format string, v ...any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.lines = append(l.lines, fmt.Sprintf(format, v...))
+}
+
+func TestDebugFeeds(t *testing.T) {
+	frames := []string{quoteFrame(TestStock, 100), `{"ok": true, "quote": "garbled"}`}
+	c, server := newFeedServer(t, nil, frames, nil, nil)
+	defer server.Close()
+
+	logger := &captureLogger{}
+	c.Logger = logger
+	c.DebugFeeds = true
+
+	quotes, errs, err := c.SubscribeQuotes(context.Background(), TestAccount, TestExchange)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	<-quotes
+	if err := <-errs; err == nil {
+		t.Error("expected the garbled frame to fail to decode")
+	}
+
+	logger.mu.Lock()
+	defer logger.mu.Unlock()
+	if len(logger.lines) != len(frames) {
+		t.Fatalf("expected %d frames logged, got %q", len(frames), logger.lines)
+	}
+	for k, frame := range frames {
+		if !strings.Contains(logger.lines[k], frame) {
+			t.Errorf("expected line %d to have the frame %s, got %s", k, frame, logger.lines[k])
+		}
+	}
+}
+
+func TestFeedManagerFanOut(t *testing.T) {
+	start := make(chan struct{})
+	conns := make(chan string, 4)
+	c, server := newFeedServer(t, start, []string{quoteFrame(TestStock, 100), quoteFrame(TestStock, 101)}, conns, nil)
+	defer server.Close()
+
+	m := NewFeedManager(context.Background(), c, TestAccount)
+	defer m.Close()
+
+	first, unsubscribeFirst, err := m.SubscribeQuotes(TestExchange)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, unsubscribeSecond, err := m.SubscribeQuotes(TestExchange)
+	if err != nil {
+		t.Fatal(err)
+	}
+	close(start)
+
+	for _, quotes := range []<-chan StockQuote{first, second} {
+		for _, last := range []int{100, 101} {
+			select {
+			case quote := <-quotes:
+				if quote.Last != last {
+					t.Errorf("expected last %d, got %d", last, quote.Last)
+				}
+			case <-time.After(time.Second):
+				t.Fatal("timed out waiting for quote")
+			}
+		}
+	}
+
+	if len(conns) != 1 {
+		t.Errorf("expected one connection, got %d", len(conns))
+	}
+
+	unsubscribeFirst()
+	if _, ok := <-first; ok {
+		t.Error("expected first subscriber's channel to close")
+	}
+
+	unsubscribeSecond()
+	if _, ok := <-second; ok {
+		t.Error("expected second subscriber's channel to close")
+	}
+
+	m.mu.Lock()
+	open := len(m.hubs)
+	m.mu.Unlock()
+	if open != 0 {
+		t.Errorf("expected the connection to close with no subscribers, %d open", open)
+	}
+}
+
+func TestSubscribeWatchlist(t *testing.T) {
+	frames := []string{
+		quoteFrame("FOOBAR", 100),
+		quoteFrame("BAZ", 200),
+		quoteFrame("QUUX", 300),
+		quoteFrame("BAZ", 201),
+		quoteFrame("OTHER", 400),
+	}
+	c, server := newFeedServer(t, nil, frames, nil, nil)
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	quotes, _, err := c.SubscribeWatchlist(ctx, TestAccount, TestExchange, []string{"BAZ", "QUUX"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []int{200, 300, 201} {
+		select {
+		case quote := <-quotes:
+			if quote.Last != want {
+				t.Errorf("expected last %d, got %+v", want, quote)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for quote")
+		}
+	}
+
+	select {
+	case quote := <-quotes:
+		t.Errorf("expected nothing else, got %+v", quote)
+	case <-time.After(20 * time.Millisecond):
+	}
+}
+
+func TestSubscribeQuotesDeadline(t *testing.T) {
+	c, server := newFeedServer(t, nil, nil, nil, nil)
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	quotes, errs, err := c.SubscribeQuotes(ctx, TestAccount, TestExchange)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case _, ok := <-quotes:
+		if ok {
+			t.Error("expected no quotes")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the feed to close at the deadline")
+	}
+
+	if err, ok := <-errs; ok {
+		t.Errorf("expected a clean shutdown, got %v", err)
+	}
+}
+
+func TestFeedManagerDeadline(t *testing.T) {
+	frames := make([]string, feedBuffer*2)
+	for k := range frames {
+		frames[k] = quoteFrame(TestStock, k)
+	}
+	c, server := newFeedServer(t, nil, frames, nil, nil)
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	m := NewFeedManager(ctx, c, TestAccount)
+
+	// never read, so the manager gets stuck handing this one quotes
+	quotes, _, err := m.SubscribeQuotes(TestExchange)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	<-ctx.Done()
+	time.Sleep(50 * time.Millisecond)
+
+	m.mu.Lock()
+	open := len(m.hubs)
+	m.mu.Unlock()
+	if open != 0 {
+		t.Errorf("expected the connection to be gone after the deadline, %d open", open)
+	}
+
+	drained := 0
+	for range quotes {
+		drained++
+	}
+	if drained > feedBuffer {
+		t.Errorf("expected at most %d buffered quotes, got %d", feedBuffer, drained)
+	}
+}
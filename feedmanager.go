@@ -0,0 +1,249 @@
+package starfighter
+
+import (
+	"context"
+	"sync"
+)
+
+// feedBuffer is how many messages a FeedManager subscriber can fall behind
+// by before it holds up the others.
+const feedBuffer = 64
+
+type feedKind int
+
+const (
+	quoteFeed feedKind = iota
+	executionFeed
+)
+
+type feedKey struct {
+	venue string
+	kind  feedKind
+}
+
+// feedSubscriber is one consumer of a shared feed. deliver blocks until the
+// message is taken, done is closed or stop is closed.
+type feedSubscriber struct {
+	deliver func(v interface{}, stop <-chan struct{})
+	finish  func()
+	done    chan struct{}
+	once    sync.Once
+}
+
+// feedHub is a single connection and everyone listening to it. mu is held
+// while a message is being handed out.
+type feedHub struct {
+	mu     sync.Mutex
+	ctx    context.Context
+	cancel context.CancelFunc
+	subs   map[*feedSubscriber]struct{}
+}
+
+// FeedManager shares websocket feeds between any number of subscribers.
+// The API has no multiplexed socket, so it keeps at most one tickertape and
+// one executions connection per venue, opening them on the first subscribe
+// and closing them when the last subscriber leaves.
+type FeedManager struct {
+	client  *Client
+	account string
+	ctx     context.Context
+	cancel  context.CancelFunc
+
+	mu   sync.Mutex
+	hubs map[feedKey]*feedHub
+	err  error
+}
+
+// NewFeedManager creates a FeedManager for the account's feeds. Everything
+// it opens is closed when ctx is done (cancelled or past its deadline) or
+// Close is called, even if a subscriber has stopped reading.
+func NewFeedManager(ctx context.Context, c *Client, account string) *FeedManager {
+	ctx, cancel := context.WithCancel(ctx)
+
+	return &FeedManager{
+		client:  c,
+		account: account,
+		ctx:     ctx,
+		cancel:  cancel,
+		hubs:    map[feedKey]*feedHub{},
+	}
+}
+
+// SubscribeQuotes adds a tickertape subscriber for the venue. Call the
+// returned function to unsubscribe; the channel is closed then, or when the
+// connection ends.
+func (m *FeedManager) SubscribeQuotes(venue string) (<-chan StockQuote, func(), error) {
+	quotes := make(chan StockQuote, feedBuffer)
+	sub := &feedSubscriber{done: make(chan struct{})}
+	sub.deliver = func(v interface{}, stop <-chan struct{}) {
+		select {
+		case quotes <- v.(StockQuote):
+		case <-sub.done:
+		case <-stop:
+		}
+	}
+	sub.finish = func() { close(quotes) }
+
+	unsubscribe, err := m.subscribe(feedKey{venue, quoteFeed}, sub)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return quotes, unsubscribe, nil
+}
+
+// SubscribeExecutions adds an executions subscriber for the venue. It works
+// the same way as SubscribeQuotes.
+func (m *FeedManager) SubscribeExecutions(venue string) (<-chan Execution, func(), error) {
+	executions := make(chan Execution, feedBuffer)
+	sub := &feedSubscriber{done: make(chan struct{})}
+	sub.deliver = func(v interface{}, stop <-chan struct{}) {
+		select {
+		case executions <- v.(Execution):
+		case <-sub.done:
+		case <-stop:
+		}
+	}
+	sub.finish = func() { close(executions) }
+
+	unsubscribe, err := m.subscribe(feedKey{venue, executionFeed}, sub)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return executions, unsubscribe, nil
+}
+
+// Err returns the error that last brought down one of the connections.
+func (m *FeedManager) Err() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.err
+}
+
+// Close closes every connection, which closes every subscriber's channel.
+func (m *FeedManager) Close() {
+	m.cancel()
+}
+
+func (m *FeedManager) subscribe(key feedKey, sub *feedSubscriber) (func(), error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	hub, ok := m.hubs[key]
+	if !ok {
+		var err error
+		if hub, err = m.open(key); err != nil {
+			return nil, err
+		}
+		m.hubs[key] = hub
+	}
+
+	hub.mu.Lock()
+	hub.subs[sub] = struct{}{}
+	hub.mu.Unlock()
+
+	return func() {
+		m.unsubscribe(key, hub, sub)
+	}, nil
+}
+
+func (m *FeedManager) unsubscribe(key feedKey, hub *feedHub, sub *feedSubscriber) {
+	sub.once.Do(func() {
+		// let go of the hub if it's waiting on us
+		close(sub.done)
+
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		hub.mu.Lock()
+		defer hub.mu.Unlock()
+
+		if _, ok := hub.subs[sub]; !ok {
+			return
+		}
+
+		delete(hub.subs, sub)
+		sub.finish()
+
+		if len(hub.subs) == 0 {
+			hub.cancel()
+			if m.hubs[key] == hub {
+				delete(m.hubs, key)
+			}
+		}
+	})
+}
+
+// open connects the feed for key and starts handing out its messages.
+func (m *FeedManager) open(key feedKey) (*feedHub, error) {
+	ctx, cancel := context.WithCancel(m.ctx)
+	hub := &feedHub{
+		ctx:    ctx,
+		cancel: cancel,
+		subs:   map[*feedSubscriber]struct{}{},
+	}
+
+	var next func() (interface{}, bool)
+	var errs <-chan error
+	var err error
+
+	switch key.kind {
+	case quoteFeed:
+		var quotes <-chan StockQuote
+		quotes, errs, err = m.client.SubscribeQuotes(ctx, m.account, key.venue)
+		next = func() (interface{}, bool) {
+			quote, ok := <-quotes
+			return quote, ok
+		}
+	case executionFeed:
+		var executions <-chan Execution
+		executions, errs, err = m.client.SubscribeExecutions(ctx, m.account, key.venue)
+		next = func() (interface{}, bool) {
+			execution, ok := <-executions
+			return execution, ok
+		}
+	}
+
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	go m.run(key, hub, next, errs)
+
+	return hub, nil
+}
+
+func (m *FeedManager) run(key feedKey, hub *feedHub, next func() (interface{}, bool), errs <-chan error) {
+	for {
+		v, ok := next()
+		if !ok {
+			break
+		}
+
+		hub.mu.Lock()
+		for sub := range hub.subs {
+			sub.deliver(v, hub.ctx.Done())
+		}
+		hub.mu.Unlock()
+	}
+
+	err := <-errs
+
+	m.mu.Lock()
+	if m.hubs[key] == hub {
+		delete(m.hubs, key)
+	}
+	if err != nil {
+		m.err = err
+	}
+	m.mu.Unlock()
+
+	hub.mu.Lock()
+	for sub := range hub.subs {
+		delete(hub.subs, sub)
+		sub.finish()
+	}
+	hub.mu.Unlock()
+	hub.cancel()
+}
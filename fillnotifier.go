@@ -0,0 +1,200 @@
+package starfighter
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// FillEvent is a fill of one of your orders.
+type FillEvent struct {
+	OrderID int
+	Venue   string
+	Symbol  string
+	Fill
+}
+
+// fillKey is what makes a fill the same fill, whichever way we heard of it.
+type fillKey struct {
+	order int
+	price int
+	qty   int
+	ts    int64
+}
+
+// FillNotifier tells you about fills of an account's orders on a venue. It
+// listens to the executions feed and also polls the account's orders, so a
+// fill missed by one still comes through the other, but only once. If the
+// feed drops it's reconnected; if polling fails it carries on.
+type FillNotifier struct {
+	client  *Client
+	account string
+	venue   string
+
+	mu sync.Mutex
+	// fills sent so far, by order, for orders still open
+	seen map[int]map[fillKey]struct{}
+	// orders seen closed, all of whose fills have been sent
+	closed map[int]struct{}
+	err    error
+}
+
+// NewFillNotifier creates a FillNotifier for the account's orders on the venue.
+func NewFillNotifier(c *Client, account, venue string) *FillNotifier {
+	return &FillNotifier{
+		client:  c,
+		account: account,
+		venue:   venue,
+		seen:    map[int]map[fillKey]struct{}{},
+		closed:  map[int]struct{}{},
+	}
+}
+
+// Run starts listening and polling (every PollInterval of the client) and
+// returns the fills, which include any from before it started that it hasn't
+// seen yet. The channel is closed once ctx is done.
+func (n *FillNotifier) Run(ctx context.Context) <-chan FillEvent {
+	fills := make(chan FillEvent)
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		n.listen(ctx, fills)
+	}()
+	go func() {
+		defer wg.Done()
+		n.poll(ctx, fills)
+	}()
+	go func() {
+		wg.Wait()
+		close(fills)
+	}()
+
+	return fills
+}
+
+// Err returns the last error from either source.
+func (n *FillNotifier) Err() error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.err
+}
+
+func (n *FillNotifier) listen(ctx context.Context, fills chan<- FillEvent) {
+	for {
+		executions, errs, err := n.client.SubscribeExecutions(ctx, n.account, n.venue)
+		if err == nil {
+			for execution := range executions {
+				n.emit(ctx, fills, FillEvent{
+					OrderID: execution.Order.ID,
+					Venue:   execution.Venue,
+					Symbol:  execution.Symbol,
+					Fill: Fill{
+						Price:     execution.Price,
+						Qty:       execution.Filled,
+						Timestamp: execution.FilledAt,
+						Direction: execution.Order.Direction,
+					},
+				})
+			}
+			err = <-errs
+		}
+
+		if err != nil {
+			n.setErr(err)
+		}
+
+		// give it a moment before reconnecting
+		if !sleep(ctx, n.client.pollInterval()) {
+			return
+		}
+	}
+}
+
+func (n *FillNotifier) poll(ctx context.Context, fills chan<- FillEvent) {
+	for {
+		list, err := n.client.listVenueOrderStatus(ctx, n.venue, n.account)
+		if err != nil {
+			n.setErr(err)
+		} else {
+			for _, order := range list.Orders {
+				for _, fill := range order.Fills {
+					fill.Direction = order.Direction
+					n.emit(ctx, fills, FillEvent{
+						OrderID: order.ID,
+						Venue:   order.Venue,
+						Symbol:  order.Symbol,
+						Fill:    fill,
+					})
+				}
+				if !order.Open {
+					n.forget(order.ID)
+				}
+			}
+		}
+
+		if !sleep(ctx, n.client.pollInterval()) {
+			return
+		}
+	}
+}
+
+// emit sends the fill on, unless it's been sent already.
+func (n *FillNotifier) emit(ctx context.Context, fills chan<- FillEvent, event FillEvent) {
+	key := fillKey{event.OrderID, event.Price, event.Qty, event.Timestamp.UnixNano()}
+
+	n.mu.Lock()
+	_, seen := n.closed[event.OrderID]
+	if !seen {
+		order := n.seen[event.OrderID]
+		if order == nil {
+			order = map[fillKey]struct{}{}
+			n.seen[event.OrderID] = order
+		}
+		_, seen = order[key]
+		order[key] = struct{}{}
+	}
+	n.mu.Unlock()
+
+	if seen {
+		return
+	}
+
+	select {
+	case fills <- event:
+	case <-ctx.Done():
+	}
+}
+
+// forget drops the fills of an order that's closed, once they've all been
+// sent, so only the order's ID is kept to ignore any heard of again.
+func (n *FillNotifier) forget(orderID int) {
+	n.mu.Lock()
+	delete(n.seen, orderID)
+	n.closed[orderID] = struct{}{}
+	n.mu.Unlock()
+}
+
+func (n *FillNotifier) setErr(err error) {
+	if err == context.Canceled || err == context.DeadlineExceeded {
+		return
+	}
+
+	n.mu.Lock()
+	n.err = err
+	n.mu.Unlock()
+}
+
+// sleep waits for d, returning false if ctx is done first.
+func sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
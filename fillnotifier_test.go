@@ -0,0 +1,95 @@
+package starfighter
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestFillNotifierDedup(t *testing.T) {
+	// the feed only hears about the first fill, polling sees both
+	execution := fmt.Sprintf(`{"ok": true, "account": %q, "venue": %q, "symbol": %q,
+		"order": {"id": 1, "direction": "buy"}, "price": 100, "filled": 5, "filledAt": "2015-12-04T09:02:16Z"}`,
+		TestAccount, TestExchange, TestStock)
+
+	c, server := newFeedServer(t, nil, []string{execution}, nil, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"ok": true, "orders": [{"id": 1, "direction": "buy", "venue": %q, "symbol": %q, "fills": [
+			{"price": 100, "qty": 5, "ts": "2015-12-04T09:02:16Z"},
+			{"price": 101, "qty": 3, "ts": "2015-12-04T09:02:17Z"}
+		]}]}`, TestExchange, TestStock)
+	})
+	defer server.Close()
+	c.PollInterval = 5 * time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	fills := NewFillNotifier(c, TestAccount, TestExchange).Run(ctx)
+
+	got := map[int]int{}
+	timeout := time.After(200 * time.Millisecond)
+	for done := false; !done; {
+		select {
+		case fill := <-fills:
+			if fill.OrderID != 1 || fill.Direction != Buy {
+				t.Errorf("unexpected fill: %+v", fill)
+			}
+			got[fill.Price]++
+		case <-timeout:
+			done = true
+		}
+	}
+
+	if len(got) != 2 || got[100] != 1 || got[101] != 1 {
+		t.Errorf("expected each fill exactly once, got %v", got)
+	}
+
+	cancel()
+	for range fills {
+	}
+}
+
+func TestFillNotifierForgetsClosedOrders(t *testing.T) {
+	c, server := newFeedServer(t, nil, nil, nil, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"ok": true, "orders": [
+			{"id": 1, "direction": "buy", "venue": %q, "symbol": %q, "open": false, "fills": [
+				{"price": 100, "qty": 5, "ts": "2015-12-04T09:02:16Z"},
+				{"price": 101, "qty": 3, "ts": "2015-12-04T09:02:17Z"}
+			]},
+			{"id": 2, "direction": "sell", "venue": %q, "symbol": %q, "open": true, "fills": [
+				{"price": 102, "qty": 1, "ts": "2015-12-04T09:02:18Z"}
+			]}
+		]}`, TestExchange, TestStock, TestExchange, TestStock)
+	})
+	defer server.Close()
+	c.PollInterval = 5 * time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	n := NewFillNotifier(c, TestAccount, TestExchange)
+	fills := n.Run(ctx)
+
+	got := 0
+	timeout := time.After(100 * time.Millisecond)
+	for done := false; !done; {
+		select {
+		case <-fills:
+			got++
+		case <-timeout:
+			done = true
+		}
+	}
+	cancel()
+	for range fills {
+	}
+
+	if got != 3 {
+		t.Errorf("expected each fill exactly once, got %d fills", got)
+	}
+	if _, ok := n.seen[1]; ok || len(n.seen[2]) != 1 {
+		t.Errorf("expected only the open order's fills kept, got %v", n.seen)
+	}
+}
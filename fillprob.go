@@ -0,0 +1,100 @@
+package starfighter
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// tapeTrade is the last trade as a quote reported it.
+type tapeTrade struct {
+	at  time.Time
+	qty int
+}
+
+// fillWindow is the client's FillWindow, or the default.
+func (c *Client) fillWindow() time.Duration {
+	if c.FillWindow <= 0 {
+		return DefaultFillWindow
+	}
+	return c.FillWindow
+}
+
+// recordTrade notes the quote's last trade, if it's one we haven't seen,
+// and forgets the ones that have dropped out of the fill window.
+func (c *Client) recordTrade(venue, stock string, quote *StockQuote) {
+	if quote.LastSize <= 0 || quote.LastTrade.IsZero() {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := venue + "/" + stock
+	trades := c.tape[key]
+	if len(trades) > 0 && !quote.LastTrade.After(trades[len(trades)-1].at) {
+		return
+	}
+
+	trades = append(trades, tapeTrade{quote.LastTrade, quote.LastSize})
+	since := c.clock().Now().Add(-c.fillWindow())
+	for len(trades) > 0 && trades[0].at.Before(since) {
+		trades = trades[1:]
+	}
+
+	if c.tape == nil {
+		c.tape = map[string][]tapeTrade{}
+	}
+	c.tape[key] = trades
+}
+
+// tradedVolume is how many shares of the stock the client has seen trade
+// in the last fill window.
+func (c *Client) tradedVolume(venue, stock string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	since := c.clock().Now().Add(-c.fillWindow())
+	volume := 0
+	for _, trade := range c.tape[venue+"/"+stock] {
+		if !trade.at.Before(since) {
+			volume += trade.qty
+		}
+	}
+	return volume
+}
+
+// FillProbability is a rough guess at the chance that an order in direction
+// side resting at price gets a fill in the next FillWindow. It takes the
+// shares that have to trade first (everything on that side of the book at
+// price or better) and the volume that traded in the last FillWindow, and
+// assumes volume keeps coming at that rate, exponentially distributed. The
+// trading is what the client has seen in quotes, so it only knows as much
+// as you've been calling QuoteStock. A price that crosses the book is 1,
+// since it'll fill right away; no trading seen is 0.
+func (c *Client) FillProbability(venue, stock string, side Direction, price int64) (float64, error) {
+	book, err := c.getStockOrderbook(context.Background(), venue, stock)
+	if err != nil {
+		return 0, err
+	}
+
+	if opposite := book.against(side); len(opposite) > 0 {
+		best := int64(opposite[0].Price)
+		if (side == Buy && price >= best) || (side == Sell && price <= best) {
+			return 1, nil
+		}
+	}
+
+	ahead := 0
+	for _, entry := range book.sorted(side) {
+		if (side == Buy && int64(entry.Price) >= price) || (side == Sell && int64(entry.Price) <= price) {
+			ahead += entry.Qty
+		}
+	}
+
+	volume := c.tradedVolume(venue, stock)
+	if volume == 0 {
+		return 0, nil
+	}
+	return math.Exp(-float64(ahead) / float64(volume)), nil
+}
@@ -0,0 +1,69 @@
+package starfighter
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFillProbability(t *testing.T) {
+	now := time.Date(2016, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	// a trade from before the window, then 100 shares within it
+	trades := []struct {
+		ago time.Duration
+		qty int
+	}{{2 * time.Minute, 500}, {50 * time.Second, 40}, {30 * time.Second, 30}, {10 * time.Second, 30}}
+	next := 0
+
+	c, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/quote") {
+			trade := trades[next]
+			next++
+			fmt.Fprintf(w, `{"ok": true, "last": 100, "lastSize": %d, "lastTrade": %q}`,
+				trade.qty, now.Add(-trade.ago).Format(time.RFC3339Nano))
+			return
+		}
+		json.NewEncoder(w).Encode(testBook())
+	})
+	defer server.Close()
+	c.timeSource = &fakeClock{now: now}
+
+	if p, err := c.FillProbability(TestExchange, TestStock, Buy, 99); err != nil || p != 0 {
+		t.Errorf("expected 0 with no trading seen, got %v (%v)", p, err)
+	}
+
+	for range trades {
+		if _, err := c.QuoteStock(TestExchange, TestStock); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// seeing the same trade again doesn't count it twice
+	next--
+	c.QuoteStock(TestExchange, TestStock)
+
+	for _, tc := range []struct {
+		side  Direction
+		price int64
+		want  float64
+	}{
+		{Buy, 99, math.Exp(-0.3)},
+		{Buy, 97, math.Exp(-0.6)},
+		{Buy, 95, math.Exp(-0.6)},
+		{Buy, 102, 1},
+		{Sell, 104, math.Exp(-0.2)},
+		{Sell, 100, 1},
+	} {
+		p, err := c.FillProbability(TestExchange, TestStock, tc.side, tc.price)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if math.Abs(p-tc.want) > 1e-9 {
+			t.Errorf("%s @ %d: expected %v, got %v", tc.side, tc.price, tc.want, p)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package starfighter
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// fixtureTemplates maps a path segment to the template segment that may
+// stand in for the value following it.
+var fixtureTemplates = map[string]string{
+	"venues": "_venue",
+	"stocks": "_stock",
+	"orders": "_order",
+}
+
+// FileTransport is a http.RoundTripper that answers requests from JSON files
+// on disk, so the client can be run offline against recorded fixtures.
+//
+// A request for /venues/TESTEX/stocks/FOOBAR/quote is served from
+// Root/venues/TESTEX/stocks/FOOBAR/quote.json. Venue, stock and order
+// segments may be stored as _venue, _stock and _order instead, which match
+// any value (exact names win). A file named with the request method, such as
+// quote.GET.json, wins over the plain one.
+//
+// Requests without a fixture get a 404 carrying an ok = false body.
+type FileTransport struct {
+	// Directory holding the fixtures
+	Root string
+	// Path prefix of the Location to strip before lookup, e.g. "/ob/api"
+	Prefix string
+}
+
+// RoundTrip serves the fixture matching the request.
+func (f *FileTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+
+	endpoint := strings.TrimPrefix(req.URL.Path, f.Prefix)
+	segments := strings.Split(strings.Trim(endpoint, "/"), "/")
+
+	for _, candidate := range fixtureCandidates(segments) {
+		for _, name := range []string{candidate + "." + req.Method + ".json", candidate + ".json"} {
+			data, err := os.ReadFile(filepath.Join(f.Root, filepath.FromSlash(name)))
+			if err == nil {
+				return fixtureResponse(req, http.StatusOK, data), nil
+			}
+			if !os.IsNotExist(err) {
+				return nil, err
+			}
+		}
+	}
+
+	body := fmt.Sprintf(`{"ok": false, "error": "no fixture for %s %s"}`, req.Method, endpoint)
+	return fixtureResponse(req, http.StatusNotFound, []byte(body)), nil
+}
+
+// fixtureCandidates lists the fixture paths for the segments, most specific first.
+func fixtureCandidates(segments []string) []string {
+	candidates := [][]string{{}}
+
+	for i, segment := range segments {
+		options := []string{segment}
+		if i > 0 {
+			if template, ok := fixtureTemplates[segments[i-1]]; ok {
+				options = append(options, template)
+			}
+		}
+
+		next := make([][]string, 0, len(candidates)*len(options))
+		for _, candidate := range candidates {
+			for _, option := range options {
+				next = append(next, append(candidate[:len(candidate):len(candidate)], option))
+			}
+		}
+		candidates = next
+	}
+
+	paths := make([]string, len(candidates))
+	for k, v := range candidates {
+		paths[k] = path.Join(v...)
+	}
+
+	return paths
+}
+
+func fixtureResponse(req *http.Request, code int, body []byte) *http.Response {
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", code, http.StatusText(code)),
+		StatusCode:    code,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": {"application/json"}},
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
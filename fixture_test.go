@@ -0,0 +1,52 @@
+package starfighter
+
+import (
+	"net/http"
+	"testing"
+)
+
+func newFixtureClient() *Client {
+	return &Client{
+		Location: "http://fixtures",
+		Client:   http.Client{Transport: &FileTransport{Root: "testdata"}},
+	}
+}
+
+func TestFileTransportQuoteStock(t *testing.T) {
+	c := newFixtureClient()
+
+	quote, err := c.QuoteStock(TestExchange, TestStock)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if quote.Symbol != TestStock || quote.Bid != 5100 || quote.Ask != 5125 {
+		t.Errorf("unexpected quote: %+v", quote)
+	}
+}
+
+func TestFileTransportGetStockOrderbook(t *testing.T) {
+	c := newFixtureClient()
+
+	book, err := c.GetStockOrderbook(TestExchange, TestStock)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(book.Bids) != 2 || len(book.Asks) != 2 {
+		t.Fatalf("unexpected book: %+v", book)
+	}
+	if book.Bids[0].Price != 5200 || book.Asks[0].Qty != 150 {
+		t.Errorf("unexpected book: %+v", book)
+	}
+}
+
+func TestFileTransportMissingFixture(t *testing.T) {
+	c := newFixtureClient()
+
+	_, err := c.GetOrderStatus(TestExchange, TestStock, 1)
+	apiErr, ok := err.(*APIError)
+	if !ok || apiErr.Code != http.StatusNotFound {
+		t.Errorf("expected a 404 APIError, got %v", err)
+	}
+}
@@ -0,0 +1,90 @@
+package starfighter
+
+import (
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	flashPricePattern   = regexp.MustCompile(`(-?)\$(-?)([\d,]+)(?:\.(\d{1,2}))?`)
+	flashSharesPattern  = regexp.MustCompile(`(?i)([\d,]+)\s+shares`)
+	flashPercentPattern = regexp.MustCompile(`(-?\d+(?:\.\d+)?)\s*%`)
+)
+
+// Flash is a message from the GM. They're written for humans, but often
+// contain numbers worth knowing about, so ParseFlash picks out what it can.
+// Message is always the real thing; the hints are a best guess.
+type Flash struct {
+	// info, success, warning or danger
+	Kind    string
+	Message string
+
+	// Dollar amounts mentioned, in cents
+	Prices []int64
+	// Share counts mentioned
+	Shares []int64
+	// Percentages mentioned
+	Percentages []float64
+}
+
+// ParseFlash builds a Flash from the message, picking out prices, share
+// counts and percentages in the order they appear.
+func ParseFlash(kind, message string) *Flash {
+	flash := &Flash{
+		Kind:    kind,
+		Message: message,
+	}
+
+	for _, match := range flashPricePattern.FindAllStringSubmatch(message, -1) {
+		dollars, err := strconv.ParseInt(strings.Replace(match[3], ",", "", -1), 10, 64)
+		if err != nil {
+			continue
+		}
+
+		cents := int64(0)
+		if match[4] != "" {
+			cents, _ = strconv.ParseInt(match[4], 10, 64)
+			if len(match[4]) == 1 {
+				cents *= 10
+			}
+		}
+
+		// the minus can go either side of the $: -$250 or $-250
+		price := dollars*100 + cents
+		if match[1] != "" || match[2] != "" {
+			price = -price
+		}
+
+		flash.Prices = append(flash.Prices, price)
+	}
+
+	for _, match := range flashSharesPattern.FindAllStringSubmatch(message, -1) {
+		if shares, err := strconv.ParseInt(strings.Replace(match[1], ",", "", -1), 10, 64); err == nil {
+			flash.Shares = append(flash.Shares, shares)
+		}
+	}
+
+	for _, match := range flashPercentPattern.FindAllStringSubmatch(message, -1) {
+		if percent, err := strconv.ParseFloat(match[1], 64); err == nil {
+			flash.Percentages = append(flash.Percentages, percent)
+		}
+	}
+
+	return flash
+}
+
+// UnmarshalJSON decodes the flash the way the GM sends it, as an object
+// with the kind as its only key, e.g. {"info": "..."}.
+func (f *Flash) UnmarshalJSON(data []byte) error {
+	messages := map[string]string{}
+	if err := json.Unmarshal(data, &messages); err != nil {
+		return err
+	}
+
+	for kind, message := range messages {
+		*f = *ParseFlash(kind, message)
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package starfighter
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestParseFlash(t *testing.T) {
+	for _, c := range []struct {
+		message     string
+		prices      []int64
+		shares      []int64
+		percentages []float64
+	}{
+		{
+			message: "Your broker has asked you to buy 100,000 shares of FOOBAR. Try to keep the average under $45.67.",
+			prices:  []int64{4567},
+			shares:  []int64{100000},
+		},
+		{
+			message: "Outstanding! You made $1,234.5 in profit, beating the target by 12.5%.",
+			prices:  []int64{123450},
+			percentages: []float64{
+				12.5,
+			},
+		},
+		{
+			message: "You've lost $-250 so far. Keep your position under 1000 shares!",
+			prices:  []int64{-25000},
+			shares:  []int64{1000},
+		},
+		{
+			message: "Down -$250 on FOOBAR and -$0.50 a share, up $12 elsewhere.",
+			prices:  []int64{-25000, -50, 1200},
+		},
+		{
+			message: "Nothing to see here.",
+		},
+	} {
+		flash := ParseFlash("info", c.message)
+
+		if flash.Message != c.message || flash.Kind != "info" {
+			t.Errorf("expected the raw message to be kept, got %+v", flash)
+		}
+		if !reflect.DeepEqual(flash.Prices, c.prices) {
+			t.Errorf("%q: expected prices %v, got %v", c.message, c.prices, flash.Prices)
+		}
+		if !reflect.DeepEqual(flash.Shares, c.shares) {
+			t.Errorf("%q: expected shares %v, got %v", c.message, c.shares, flash.Shares)
+		}
+		if !reflect.DeepEqual(flash.Percentages, c.percentages) {
+			t.Errorf("%q: expected percentages %v, got %v", c.message, c.percentages, flash.Percentages)
+		}
+	}
+}
+
+func TestFlashUnmarshalJSON(t *testing.T) {
+	flash := Flash{}
+	if err := json.Unmarshal([]byte(`{"warning": "Sell 500 shares now"}`), &flash); err != nil {
+		t.Fatal(err)
+	}
+
+	if flash.Kind != "warning" || flash.Message != "Sell 500 shares now" || !reflect.DeepEqual(flash.Shares, []int64{500}) {
+		t.Errorf("unexpected flash: %+v", flash)
+	}
+}
@@ -0,0 +1,68 @@
+package starfighter
+
+import (
+	"sync"
+	"time"
+)
+
+// FlickerDetector watches a stock's quotes for the bid or ask changing back
+// and forth faster than anyone could trade on it, which usually means bots
+// fighting over the touch or a feed that's gone funny. Strategies should
+// widen out or sit on their hands while it's going on. Feed it from the
+// tickertape; it's safe to do so from several goroutines.
+type FlickerDetector struct {
+	mu         sync.Mutex
+	maxChanges int
+	seen       bool
+	bid, ask   int
+	changes    []time.Time
+}
+
+// NewFlickerDetector creates a FlickerDetector that flags more than
+// maxChanges changes to the bid or ask in a second.
+func NewFlickerDetector(maxChanges int) *FlickerDetector {
+	return &FlickerDetector{maxChanges: maxChanges}
+}
+
+// AddQuote counts the quote if its bid or ask is different from the last
+// one's. The first quote has nothing to change from, so it doesn't count.
+// Quotes are timed by their QuoteAt, or when they're added if they don't
+// have one.
+func (f *FlickerDetector) AddQuote(quote StockQuote) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	changed := f.seen && (quote.Bid != f.bid || quote.Ask != f.ask)
+	f.seen, f.bid, f.ask = true, quote.Bid, quote.Ask
+	if !changed {
+		return
+	}
+
+	at := quote.QuoteAt
+	if at.IsZero() {
+		at = time.Now()
+	}
+
+	f.changes = append(f.changes, at)
+	f.drop(at)
+}
+
+// IsFlickering says whether there were more than maxChanges changes in the
+// second up to now, which goes by the same clock as the quotes' QuoteAt
+// (time.Now() for a live feed). A feed that goes quiet stops flickering
+// once its last burst is a second old.
+func (f *FlickerDetector) IsFlickering(now time.Time) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.drop(now)
+	return len(f.changes) > f.maxChanges
+}
+
+// drop forgets changes more than a second before now; only the last second
+// matters.
+func (f *FlickerDetector) drop(now time.Time) {
+	for len(f.changes) > 0 && now.Sub(f.changes[0]) >= time.Second {
+		f.changes = f.changes[1:]
+	}
+}
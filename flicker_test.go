@@ -0,0 +1,62 @@
+package starfighter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFlickerDetector(t *testing.T) {
+	start := time.Date(2016, 1, 1, 12, 0, 0, 0, time.UTC)
+	f := NewFlickerDetector(5)
+
+	// the same quote over and over isn't flicker
+	for k := 0; k < 20; k++ {
+		f.AddQuote(StockQuote{Bid: 100, Ask: 102, QuoteAt: start.Add(time.Duration(k) * 10 * time.Millisecond)})
+	}
+	if f.IsFlickering(start.Add(200 * time.Millisecond)) {
+		t.Error("expected an unchanging quote not to flicker")
+	}
+
+	// the ask flipping between 102 and 101 every 100ms
+	at := start.Add(time.Second)
+	for k := 0; k < 8; k++ {
+		at = at.Add(100 * time.Millisecond)
+		f.AddQuote(StockQuote{Bid: 100, Ask: 101 + k%2, QuoteAt: at})
+	}
+	if !f.IsFlickering(at) {
+		t.Error("expected 8 changes in a second to be flicker")
+	}
+
+	// and then settling down
+	for k := 0; k < 3; k++ {
+		at = at.Add(500 * time.Millisecond)
+		f.AddQuote(StockQuote{Bid: 100 + k, Ask: 103, QuoteAt: at})
+	}
+	if f.IsFlickering(at) {
+		t.Error("expected it to settle down")
+	}
+}
+
+func TestFlickerDetectorQuiet(t *testing.T) {
+	start := time.Date(2016, 1, 1, 12, 0, 0, 0, time.UTC)
+	f := NewFlickerDetector(2)
+
+	// three quotes are only two changes; the first isn't a change from
+	// nothing
+	for k := 0; k < 3; k++ {
+		f.AddQuote(StockQuote{Bid: 100 + k, Ask: 105, QuoteAt: start.Add(time.Duration(k) * 10 * time.Millisecond)})
+	}
+	if f.IsFlickering(start.Add(20 * time.Millisecond)) {
+		t.Error("expected the first quote not to count as a change")
+	}
+
+	f.AddQuote(StockQuote{Bid: 100, Ask: 105, QuoteAt: start.Add(30 * time.Millisecond)})
+	if !f.IsFlickering(start.Add(30 * time.Millisecond)) {
+		t.Error("expected 3 changes to be flicker")
+	}
+
+	// no more quotes come in
+	if f.IsFlickering(start.Add(2 * time.Second)) {
+		t.Error("expected a quiet feed to stop flickering")
+	}
+}
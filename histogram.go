@@ -0,0 +1,84 @@
+package starfighter
+
+import (
+	"math"
+	"math/bits"
+	"sync"
+)
+
+// FillSizeHistogram counts fill sizes in exponentially growing buckets
+// (1, 2-3, 4-7, 8-15, ...), to get a feel for how big trades on a venue
+// usually are. It's safe to feed from several goroutines.
+type FillSizeHistogram struct {
+	mu      sync.Mutex
+	buckets [64]int
+	count   int
+	max     int
+}
+
+// Add counts a fill of qty shares. Empty fills are ignored.
+func (h *FillSizeHistogram) Add(qty int) {
+	if qty <= 0 {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.buckets[bits.Len(uint(qty))-1]++
+	h.count++
+	if qty > h.max {
+		h.max = qty
+	}
+}
+
+// AddExecution counts the fill from the executions feed.
+func (h *FillSizeHistogram) AddExecution(execution Execution) {
+	h.Add(execution.Filled)
+}
+
+// AddOrder counts each of the order's fills.
+func (h *FillSizeHistogram) AddOrder(order OrderResultAlt) {
+	for _, fill := range order.Fills {
+		h.Add(fill.Qty)
+	}
+}
+
+// Count is the number of fills counted.
+func (h *FillSizeHistogram) Count() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.count
+}
+
+// Percentile returns the size that p percent (0-100) of fills are no larger
+// than, to the resolution of the buckets: it's the top of the bucket the
+// percentile lands in, or the largest fill seen if that's smaller.
+// It's 0 if nothing has been counted.
+func (h *FillSizeHistogram) Percentile(p float64) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.count == 0 {
+		return 0
+	}
+
+	rank := int(math.Ceil(p / 100 * float64(h.count)))
+	if rank < 1 {
+		rank = 1
+	}
+
+	seen := 0
+	for k, n := range h.buckets {
+		seen += n
+		if seen >= rank {
+			upper := 1<<uint(k+1) - 1
+			if upper > h.max {
+				upper = h.max
+			}
+			return upper
+		}
+	}
+
+	return h.max
+}
@@ -0,0 +1,39 @@
+package starfighter
+
+import "testing"
+
+func TestFillSizeHistogramPercentile(t *testing.T) {
+	h := &FillSizeHistogram{}
+
+	if p := h.Percentile(50); p != 0 {
+		t.Errorf("expected 0 with nothing counted, got %d", p)
+	}
+
+	for i := 0; i < 50; i++ {
+		h.Add(10)
+	}
+	for i := 0; i < 40; i++ {
+		h.AddExecution(Execution{Filled: 100})
+	}
+	h.AddOrder(OrderResultAlt{Fills: []Fill{{Qty: 1000}, {Qty: 1000}}})
+	for i := 0; i < 8; i++ {
+		h.Add(1000)
+	}
+
+	if h.Count() != 100 {
+		t.Fatalf("expected 100 fills, got %d", h.Count())
+	}
+
+	for _, c := range []struct {
+		p    float64
+		want int
+	}{
+		{50, 15},
+		{90, 127},
+		{100, 1000},
+	} {
+		if got := h.Percentile(c.p); got != c.want {
+			t.Errorf("p%v: expected %d, got %d", c.p, c.want, got)
+		}
+	}
+}
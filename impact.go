@@ -0,0 +1,96 @@
+package starfighter
+
+import "sync"
+
+// impactSample is the signed volume traded between two quotes and how far
+// the midpoint moved over the same time.
+type impactSample struct {
+	flow  float64
+	moved float64
+}
+
+// PriceImpactEstimator estimates Kyle's lambda: how far the midpoint moves
+// per share of net buying, found by regressing the change in the mid
+// between quotes on the signed volume traded in between. Trades are signed
+// the same way as for OrderFlowTracker. Feed it quotes and executions from
+// the feeds; it's safe to do so from several goroutines.
+type PriceImpactEstimator struct {
+	mu      sync.Mutex
+	window  int
+	bid     int
+	ask     int
+	mid     float64
+	flow    float64
+	samples []impactSample
+}
+
+// NewPriceImpactEstimator creates a PriceImpactEstimator over the last
+// window quote-to-quote intervals.
+func NewPriceImpactEstimator(window int) *PriceImpactEstimator {
+	return &PriceImpactEstimator{window: window}
+}
+
+// AddQuote closes off the interval since the last quote. Quotes missing a
+// side update the classification but don't count.
+func (e *PriceImpactEstimator) AddQuote(quote StockQuote) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.bid, e.ask = quote.Bid, quote.Ask
+	if quote.Bid <= 0 || quote.Ask <= 0 {
+		return
+	}
+
+	mid := float64(quote.Bid+quote.Ask) / 2
+	if e.mid > 0 {
+		e.samples = append(e.samples, impactSample{e.flow, mid - e.mid})
+		if len(e.samples) > e.window {
+			e.samples = e.samples[len(e.samples)-e.window:]
+		}
+	}
+	e.mid, e.flow = mid, 0
+}
+
+// AddExecution adds the trade's signed volume to the current interval.
+func (e *PriceImpactEstimator) AddExecution(execution Execution) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	volume := float64(execution.Filled)
+	if aggressor(e.bid, e.ask, execution) == Sell {
+		volume = -volume
+	}
+	e.flow += volume
+}
+
+// Lambda is the estimated price impact in cents per share, the slope of the
+// least squares fit. It's 0 until there are two intervals with different
+// flows to fit.
+func (e *PriceImpactEstimator) Lambda() float64 {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	n := float64(len(e.samples))
+	if n < 2 {
+		return 0
+	}
+
+	meanFlow, meanMoved := 0.0, 0.0
+	for _, s := range e.samples {
+		meanFlow += s.flow
+		meanMoved += s.moved
+	}
+	meanFlow /= n
+	meanMoved /= n
+
+	covariance, variance := 0.0, 0.0
+	for _, s := range e.samples {
+		covariance += (s.flow - meanFlow) * (s.moved - meanMoved)
+		variance += (s.flow - meanFlow) * (s.flow - meanFlow)
+	}
+
+	if variance == 0 {
+		return 0
+	}
+	return covariance / variance
+}
@@ -0,0 +1,35 @@
+package starfighter
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPriceImpactEstimator(t *testing.T) {
+	e := NewPriceImpactEstimator(10)
+
+	// every share of net buying moves the mid half a cent
+	bid, ask := 1000, 1002
+	e.AddQuote(StockQuote{Bid: bid, Ask: ask})
+
+	for _, flow := range []int{10, -20, 30, 0, -10, 40, -6} {
+		if flow > 0 {
+			e.AddExecution(Execution{Price: ask, Filled: flow / 2})
+			e.AddExecution(Execution{Price: ask, Filled: flow - flow/2})
+		} else if flow < 0 {
+			e.AddExecution(Execution{Price: bid, Filled: -flow})
+		}
+
+		bid += flow / 2
+		ask += flow / 2
+		e.AddQuote(StockQuote{Bid: bid, Ask: ask})
+	}
+
+	if lambda := e.Lambda(); math.Abs(lambda-0.5) > 1e-9 {
+		t.Errorf("expected lambda 0.5, got %v", lambda)
+	}
+
+	if lambda := NewPriceImpactEstimator(10).Lambda(); lambda != 0 {
+		t.Errorf("expected 0 with nothing to go on, got %v", lambda)
+	}
+}
@@ -0,0 +1,154 @@
+package starfighter
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// requestRateWindow is how far back RequestRate looks.
+const requestRateWindow = 10 * time.Second
+
+// rateLimiter is a token bucket: it lets through rate requests a second on
+// average, and up to burst at once.
+type rateLimiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newRateLimiter(rate float64, burst int) *rateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &rateLimiter{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// Wait blocks until a request is allowed through, or ctx is done.
+func (l *rateLimiter) Wait(ctx context.Context) error {
+	l.mu.Lock()
+	now := time.Now()
+	l.tokens = math.Min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+
+	// take a token now, even if it means going into debt for it
+	l.tokens--
+	wait := time.Duration(0)
+	if l.tokens < 0 {
+		wait = time.Duration(-l.tokens / l.rate * float64(time.Second))
+	}
+	l.mu.Unlock()
+
+	if wait == 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		// didn't use it after all
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// SetRateLimit caps the client at rps requests a second, with bursts of up
+// to burst requests. Requests over the limit wait their turn. A rps of zero
+// or less removes the limit.
+func (c *Client) SetRateLimit(rps float64, burst int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if rps <= 0 {
+		c.limiter = nil
+		return
+	}
+	c.limiter = newRateLimiter(rps, burst)
+}
+
+// waitRateLimit waits for the client's rate limit, if it has one.
+func (c *Client) waitRateLimit(ctx context.Context) error {
+	c.mu.Lock()
+	limiter := c.limiter
+	c.mu.Unlock()
+
+	if limiter == nil {
+		return nil
+	}
+	return limiter.Wait(ctx)
+}
+
+// SetSymbolRateLimit caps the orders placed for the symbol at rps a second,
+// with bursts of up to burst, so one stock can't be flooded with orders.
+// It's on top of the client's rate limit, which every request still waits
+// for. A rps of zero or less removes the limit.
+func (c *Client) SetSymbolRateLimit(symbol string, rps float64, burst int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if rps <= 0 {
+		delete(c.symbolLimiters, symbol)
+		return
+	}
+
+	if c.symbolLimiters == nil {
+		c.symbolLimiters = map[string]*rateLimiter{}
+	}
+	c.symbolLimiters[symbol] = newRateLimiter(rps, burst)
+}
+
+// waitSymbolRateLimit waits for the symbol's rate limit, if it has one.
+func (c *Client) waitSymbolRateLimit(ctx context.Context, symbol string) error {
+	c.mu.Lock()
+	limiter := c.symbolLimiters[symbol]
+	c.mu.Unlock()
+
+	if limiter == nil {
+		return nil
+	}
+	return limiter.Wait(ctx)
+}
+
+// countRequest notes a request going out, for RequestRate.
+func (c *Client) countRequest() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.clock().Now()
+	c.requests = append(c.requests, now)
+	for len(c.requests) > 0 && now.Sub(c.requests[0]) >= requestRateWindow {
+		c.requests = c.requests[1:]
+	}
+}
+
+// RequestRate is how many requests a second the client has been sending
+// the API, averaged over the last 10 seconds, to see how close you're
+// running to the API's limits. Every request counts, whatever it's for.
+func (c *Client) RequestRate() float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.clock().Now()
+	sent := 0
+	for _, at := range c.requests {
+		if now.Sub(at) < requestRateWindow {
+			sent++
+		}
+	}
+	return float64(sent) / requestRateWindow.Seconds()
+}
@@ -0,0 +1,94 @@
+package starfighter
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter(t *testing.T) {
+	l := newRateLimiter(100, 2)
+
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		if err := l.Wait(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// two go straight through, the next two wait 10ms each
+	if elapsed := time.Since(start); elapsed < 15*time.Millisecond {
+		t.Errorf("expected to be held up, took %v", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	l.Wait(context.Background())
+	if err := l.Wait(ctx); err != context.Canceled {
+		t.Errorf("expected cancellation, got %v", err)
+	}
+}
+
+func TestSymbolRateLimit(t *testing.T) {
+	c, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"ok": true, "id": 1, "open": true}`)
+	})
+	defer server.Close()
+	c.SetSymbolRateLimit(TestStock, 20, 2)
+
+	place := func(stock string, n int) time.Duration {
+		start := time.Now()
+		for i := 0; i < n; i++ {
+			if _, err := c.PlaceStockOrder(TestAccount, TestExchange, stock, 100, 1, "buy", "limit"); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return time.Since(start)
+	}
+
+	// two go straight through, the next three wait 50ms each
+	if elapsed := place(TestStock, 5); elapsed < 140*time.Millisecond {
+		t.Errorf("expected %s to be held up, took %v", TestStock, elapsed)
+	}
+
+	// other symbols aren't limited
+	if elapsed := place("OTHER", 5); elapsed > 100*time.Millisecond {
+		t.Errorf("expected OTHER to go straight through, took %v", elapsed)
+	}
+
+	c.SetSymbolRateLimit(TestStock, 0, 0)
+	if elapsed := place(TestStock, 5); elapsed > 100*time.Millisecond {
+		t.Errorf("expected the limit to be gone, took %v", elapsed)
+	}
+}
+
+func TestRequestRate(t *testing.T) {
+	c, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"ok": true}`)
+	})
+	defer server.Close()
+
+	clk := &fakeClock{now: time.Date(2016, 1, 1, 12, 0, 0, 0, time.UTC)}
+	c.timeSource = clk
+
+	// 10 calls, then 20 more 5 seconds later
+	for k := 0; k < 30; k++ {
+		if k == 10 {
+			clk.After(5 * time.Second)
+		}
+		if _, _, err := c.Call("GET", "/heartbeat", nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if rate := c.RequestRate(); rate != 3 {
+		t.Errorf("expected 3 requests a second, got %v", rate)
+	}
+
+	// the first 10 drop out of the window
+	clk.After(6 * time.Second)
+	if rate := c.RequestRate(); rate != 2 {
+		t.Errorf("expected 2 requests a second, got %v", rate)
+	}
+}
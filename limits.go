@@ -0,0 +1,102 @@
+package starfighter
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrPositionLimit is returned for orders that could take a position past
+// its limit.
+var ErrPositionLimit = errors.New("starfighter: order would breach the position limit")
+
+// SetPositionLimit caps how many shares of the symbol the client will let
+// you be long or short. PlaceStockOrder turns down orders that could take
+// the position past it if they, and every other open order on the same
+// side, filled completely. Zero or less takes the limit off.
+func (c *Client) SetPositionLimit(symbol string, maxShares int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if maxShares <= 0 {
+		delete(c.positionLimits, symbol)
+		return
+	}
+
+	if c.positionLimits == nil {
+		c.positionLimits = map[string]int64{}
+	}
+	c.positionLimits[symbol] = maxShares
+}
+
+// positionSide is one side of a symbol's book, for pending orders.
+type positionSide struct {
+	symbol string
+	sell   bool
+}
+
+// reservePosition is the check PlaceStockOrder does. It goes by the
+// tracked position and orders, so it can't see anything placed elsewhere.
+// Orders that pass hold their qty against the limit until release is
+// called, so orders placed at the same time can't breach it together.
+func (c *Client) reservePosition(stock string, qty int64, direction string) (release func(), err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	limit, ok := c.positionLimits[stock]
+	if !ok {
+		return func() {}, nil
+	}
+
+	side := positionSide{stock, Direction(direction) == Sell}
+
+	worst := qty + c.pendingQty[side]
+	for _, order := range c.orders {
+		if order.Symbol == stock && (order.Direction == Sell) == side.sell {
+			worst += int64(order.Qty)
+		}
+	}
+	if side.sell {
+		worst = -worst
+	}
+	worst += c.positions[stock]
+
+	if worst > limit || worst < -limit {
+		return nil, fmt.Errorf("%w: could reach %d shares of %s (limit %d)", ErrPositionLimit, worst, stock, limit)
+	}
+
+	if c.pendingQty == nil {
+		c.pendingQty = map[positionSide]int64{}
+	}
+	c.pendingQty[side] += qty
+
+	return func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		if c.pendingQty[side] -= qty; c.pendingQty[side] == 0 {
+			delete(c.pendingQty, side)
+		}
+	}, nil
+}
+
+// WouldSelfCross says whether an order in direction side at price would
+// trade against one of your own resting orders on the other side of the
+// stock's book, making a wash trade. It only goes by ownOrders, so pass in
+// what's open, e.g. from AllOpenOrders.
+func (c *Client) WouldSelfCross(venue, stock string, side Direction, price int64, ownOrders []OrderResultAlt) bool {
+	for _, order := range ownOrders {
+		if !order.Open || order.Qty <= 0 || order.Venue != venue || order.Symbol != stock {
+			continue
+		}
+
+		resting := int64(order.Price)
+		switch {
+		case side == Buy && order.Direction == Sell && resting <= price:
+			return true
+		case side == Sell && order.Direction == Buy && resting >= price:
+			return true
+		}
+	}
+
+	return false
+}
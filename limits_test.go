@@ -0,0 +1,138 @@
+package starfighter
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPositionLimit(t *testing.T) {
+	nextID := 0
+	c, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		order := orderRequest{}
+		json.NewDecoder(r.Body).Decode(&order)
+
+		nextID++
+		// the first order fills straight away, the rest rest
+		if nextID == 1 {
+			fmt.Fprintf(w, `{"ok": true, "id": %d, "symbol": %q, "direction": "buy", "qty": 0, "totalFilled": 40, "open": false}`, nextID, TestStock)
+			return
+		}
+		fmt.Fprintf(w, `{"ok": true, "id": %d, "symbol": %q, "direction": %q, "qty": %d, "open": true}`, nextID, TestStock, order.Direction, order.Qty)
+	})
+	defer server.Close()
+
+	c.SetPositionLimit(TestStock, 100)
+
+	place := func(qty int64, direction Direction) error {
+		_, err := c.PlaceStockOrder(TestAccount, TestExchange, TestStock, 100, qty, string(direction), string(Limit))
+		return err
+	}
+
+	if err := place(40, Buy); err != nil {
+		t.Fatal(err)
+	}
+	if err := place(30, Buy); err != nil {
+		t.Fatal(err)
+	}
+
+	// 40 held and 30 open is 70; another 30 is right at the limit
+	if err := place(31, Buy); !errors.Is(err, ErrPositionLimit) {
+		t.Errorf("expected ErrPositionLimit, got %v", err)
+	}
+	if err := place(30, Buy); err != nil {
+		t.Errorf("expected an order up to the limit to go through, got %v", err)
+	}
+	if err := place(1, Buy); !errors.Is(err, ErrPositionLimit) {
+		t.Errorf("expected ErrPositionLimit, got %v", err)
+	}
+
+	// selling only takes the position down, until it goes short past it
+	if err := place(100, Sell); err != nil {
+		t.Errorf("expected a sell to go through, got %v", err)
+	}
+	if err := place(41, Sell); !errors.Is(err, ErrPositionLimit) {
+		t.Errorf("expected ErrPositionLimit going short, got %v", err)
+	}
+
+	c.SetPositionLimit(TestStock, 0)
+	if err := place(1000, Buy); err != nil {
+		t.Errorf("expected no limit once it's taken off, got %v", err)
+	}
+}
+
+func TestPositionLimitConcurrent(t *testing.T) {
+	var nextID int64
+	c, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		order := orderRequest{}
+		json.NewDecoder(r.Body).Decode(&order)
+
+		// slow enough that every order is checked before any is tracked
+		time.Sleep(20 * time.Millisecond)
+		fmt.Fprintf(w, `{"ok": true, "id": %d, "symbol": %q, "direction": %q, "qty": %d, "open": true}`, atomic.AddInt64(&nextID, 1), TestStock, order.Direction, order.Qty)
+	})
+	defer server.Close()
+
+	c.SetPositionLimit(TestStock, 100)
+
+	var placed int64
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := c.PlaceStockOrder(TestAccount, TestExchange, TestStock, 100, 10, string(Buy), string(Limit)); err == nil {
+				atomic.AddInt64(&placed, 10)
+			} else if !errors.Is(err, ErrPositionLimit) {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if placed != 100 {
+		t.Errorf("expected orders for exactly the 100 share limit, got %d", placed)
+	}
+
+	// nothing is left held once the orders are tracked
+	c.SetPositionLimit(TestStock, 0)
+	c.mu.Lock()
+	pending := len(c.pendingQty)
+	c.mu.Unlock()
+	if pending != 0 {
+		t.Errorf("expected no pending qty, got %d sides", pending)
+	}
+}
+
+func TestWouldSelfCross(t *testing.T) {
+	c := &Client{}
+	own := []OrderResultAlt{
+		{Venue: TestExchange, Symbol: TestStock, Direction: Buy, Price: 100, Qty: 10, Open: true},
+		{Venue: TestExchange, Symbol: TestStock, Direction: Buy, Price: 98, Qty: 10, Open: true},
+		{Venue: TestExchange, Symbol: TestStock, Direction: Sell, Price: 105, Qty: 10, Open: true},
+		{Venue: TestExchange, Symbol: TestStock, Direction: Sell, Price: 103, Qty: 0, Open: false},
+		{Venue: TestExchange, Symbol: "OTHER", Direction: Buy, Price: 110, Qty: 10, Open: true},
+	}
+
+	for _, tc := range []struct {
+		side    Direction
+		price   int64
+		crosses bool
+	}{
+		{Sell, 100, true},
+		{Sell, 99, true},
+		{Sell, 101, false},
+		{Buy, 105, true},
+		{Buy, 104, false},
+		{Buy, 103, false},
+	} {
+		if crosses := c.WouldSelfCross(TestExchange, TestStock, tc.side, tc.price, own); crosses != tc.crosses {
+			t.Errorf("%s @ %d: expected %v, got %v", tc.side, tc.price, tc.crosses, crosses)
+		}
+	}
+}
@@ -0,0 +1,421 @@
+package starfighter
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MockVenue is an in-memory stand-in for the API, for testing bots without
+// a level running. It serves heartbeats, stock lists, orderbooks, quotes and
+// placing, checking on, listing and cancelling orders, on any venue and
+// stock you ask for, and matches orders the way the real thing does: best
+// price first, then first come first served, at the resting order's price.
+// There are no feeds and no other traders but the ones you bring.
+type MockVenue struct {
+	// Server is the test server the mock runs on
+	Server *httptest.Server
+
+	mu     sync.Mutex
+	nextID int
+	books  map[string]*mockBook
+	orders map[int]*OrderResultAlt
+}
+
+// mockBook is one stock's orders and trades. bids and asks are kept best
+// price first, then oldest first.
+type mockBook struct {
+	venue, symbol string
+	bids, asks    []*OrderResultAlt
+	last          int
+	lastSize      int
+	lastTrade     time.Time
+}
+
+// mockOrderResponse answers with both of the API's names for the order type,
+// so it decodes into OrderResult and OrderResultAlt alike.
+type mockOrderResponse struct {
+	OK bool `json:"ok"`
+	OrderResultAlt
+	Type string `json:"type"`
+}
+
+// NewMockVenue starts a MockVenue. Close it when you're done.
+func NewMockVenue() *MockVenue {
+	m := &MockVenue{
+		books:  map[string]*mockBook{},
+		orders: map[int]*OrderResultAlt{},
+	}
+
+	m.Server = httptest.NewServer(http.HandlerFunc(m.route))
+	return m
+}
+
+// NewClient returns a Client pointed at the mock.
+func (m *MockVenue) NewClient() *Client {
+	return &Client{Location: m.Server.URL}
+}
+
+// Close shuts the mock's server down.
+func (m *MockVenue) Close() {
+	m.Server.Close()
+}
+
+// mockPath is the parts of a request's path its handler needs, by the name
+// the route gave them.
+type mockPath map[string]string
+
+// route sends the request to its handler, with the parts of the path it
+// needs.
+func (m *MockVenue) route(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	var p mockPath
+	match := func(method string, pattern ...string) bool {
+		if r.Method != method || len(parts) != len(pattern) {
+			return false
+		}
+		p = mockPath{}
+		for k, part := range pattern {
+			if strings.HasPrefix(part, ":") {
+				p[part[1:]] = parts[k]
+			} else if part != parts[k] {
+				return false
+			}
+		}
+		return true
+	}
+
+	switch {
+	case match("GET", "heartbeat"), match("GET", "venues", ":venue", "heartbeat"):
+		m.heartbeat(w, r, p)
+	case match("GET", "venues", ":venue", "stocks"):
+		m.stocks(w, r, p)
+	case match("GET", "venues", ":venue", "stocks", ":stock"):
+		m.orderbook(w, r, p)
+	case match("GET", "venues", ":venue", "stocks", ":stock", "quote"):
+		m.quote(w, r, p)
+	case match("POST", "venues", ":venue", "stocks", ":stock", "orders"):
+		m.place(w, r, p)
+	case match("GET", "venues", ":venue", "stocks", ":stock", "orders", ":id"):
+		m.status(w, r, p)
+	case match("DELETE", "venues", ":venue", "stocks", ":stock", "orders", ":id"),
+		match("POST", "venues", ":venue", "stocks", ":stock", "orders", ":id", "cancel"):
+		m.cancel(w, r, p)
+	case match("GET", "venues", ":venue", "accounts", ":account", "orders"),
+		match("GET", "venues", ":venue", "accounts", ":account", "stocks", ":stock", "orders"):
+		m.list(w, r, p)
+	default:
+		mockError(w, http.StatusNotFound, "Not found: "+r.Method+" "+r.URL.Path)
+	}
+}
+
+func (m *MockVenue) heartbeat(w http.ResponseWriter, r *http.Request, p mockPath) {
+	mockReply(w, http.StatusOK, map[string]interface{}{"ok": true, "venue": p["venue"]})
+}
+
+func (m *MockVenue) stocks(w http.ResponseWriter, r *http.Request, p mockPath) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	symbols := []Stock{}
+	for _, book := range m.books {
+		if book.venue == p["venue"] {
+			symbols = append(symbols, Stock{Name: book.symbol, Symbol: book.symbol})
+		}
+	}
+	sort.Slice(symbols, func(i, j int) bool { return symbols[i].Symbol < symbols[j].Symbol })
+
+	mockReply(w, http.StatusOK, map[string]interface{}{"ok": true, "symbols": symbols})
+}
+
+func (m *MockVenue) orderbook(w http.ResponseWriter, r *http.Request, p mockPath) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	book := m.book(p["venue"], p["stock"])
+	entries := func(orders []*OrderResultAlt) []BookEntry {
+		out := make([]BookEntry, len(orders))
+		for k, order := range orders {
+			out[k] = BookEntry{IsBuy: order.Direction == Buy, Price: order.Price, Qty: order.Qty}
+		}
+		return out
+	}
+
+	mockReply(w, http.StatusOK, struct {
+		OK bool `json:"ok"`
+		OrderBook
+	}{true, OrderBook{
+		Venue:     book.venue,
+		Symbol:    book.symbol,
+		Bids:      entries(book.bids),
+		Asks:      entries(book.asks),
+		Timestamp: time.Now(),
+	}})
+}
+
+func (m *MockVenue) quote(w http.ResponseWriter, r *http.Request, p mockPath) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	book := m.book(p["venue"], p["stock"])
+	quote := StockQuote{
+		Venue:     book.venue,
+		Symbol:    book.symbol,
+		Last:      book.last,
+		LastSize:  book.lastSize,
+		LastTrade: book.lastTrade,
+		QuoteAt:   time.Now(),
+	}
+	quote.Bid, quote.BidSize, quote.BidDepth = mockTouch(book.bids)
+	quote.Ask, quote.AskSize, quote.AskDepth = mockTouch(book.asks)
+
+	mockReply(w, http.StatusOK, struct {
+		OK bool `json:"ok"`
+		StockQuote
+	}{true, quote})
+}
+
+func (m *MockVenue) place(w http.ResponseWriter, r *http.Request, p mockPath) {
+	req := orderRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		mockError(w, http.StatusBadRequest, "Couldn't parse the order: "+err.Error())
+		return
+	}
+
+	switch {
+	case req.Qty <= 0:
+		mockError(w, http.StatusBadRequest, "Qty must be positive")
+		return
+	case req.Price < 0:
+		mockError(w, http.StatusBadRequest, "Price must be non-negative")
+		return
+	case Direction(req.Direction) != Buy && Direction(req.Direction) != Sell:
+		mockError(w, http.StatusBadRequest, fmt.Sprintf("Unknown direction %q", req.Direction))
+		return
+	}
+	switch OrderType(req.OrderType) {
+	case Limit, Market, FillOrKill, ImmediateOrCancel:
+	default:
+		mockError(w, http.StatusBadRequest, fmt.Sprintf("Unknown order type %q", req.OrderType))
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.nextID++
+	order := &OrderResultAlt{
+		Symbol:      p["stock"],
+		Venue:       p["venue"],
+		Direction:   Direction(req.Direction),
+		OriginalQty: int(req.Qty),
+		Qty:         int(req.Qty),
+		Price:       int(req.Price),
+		Type:        req.OrderType,
+		ID:          m.nextID,
+		Account:     req.Account,
+		Timestamp:   time.Now(),
+		Fills:       []Fill{},
+		Open:        true,
+	}
+	m.orders[order.ID] = order
+
+	m.match(m.book(order.Venue, order.Symbol), order)
+
+	mockReply(w, http.StatusOK, mockOrderResponse{true, *order, order.Type})
+}
+
+func (m *MockVenue) status(w http.ResponseWriter, r *http.Request, p mockPath) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	order, ok := m.order(w, p)
+	if !ok {
+		return
+	}
+
+	mockReply(w, http.StatusOK, mockOrderResponse{true, *order, order.Type})
+}
+
+func (m *MockVenue) cancel(w http.ResponseWriter, r *http.Request, p mockPath) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	order, ok := m.order(w, p)
+	if !ok {
+		return
+	}
+
+	if order.Open {
+		book := m.book(order.Venue, order.Symbol)
+		book.bids = mockRemove(book.bids, order)
+		book.asks = mockRemove(book.asks, order)
+		order.Qty = 0
+		order.Open = false
+	}
+
+	mockReply(w, http.StatusOK, mockOrderResponse{true, *order, order.Type})
+}
+
+func (m *MockVenue) list(w http.ResponseWriter, r *http.Request, p mockPath) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	orders := []OrderResultAlt{}
+	for _, order := range m.orders {
+		if order.Venue != p["venue"] || order.Account != p["account"] {
+			continue
+		}
+		if stock := p["stock"]; stock != "" && order.Symbol != stock {
+			continue
+		}
+		orders = append(orders, *order)
+	}
+	sort.Slice(orders, func(i, j int) bool { return orders[i].ID < orders[j].ID })
+
+	mockReply(w, http.StatusOK, map[string]interface{}{"ok": true, "venue": p["venue"], "orders": orders})
+}
+
+// order finds the order the request is about, answering with an error if
+// there's no such order on that venue and stock.
+func (m *MockVenue) order(w http.ResponseWriter, p mockPath) (*OrderResultAlt, bool) {
+	id, err := strconv.Atoi(p["id"])
+	order, ok := m.orders[id]
+	if err != nil || !ok || order.Venue != p["venue"] || order.Symbol != p["stock"] {
+		mockError(w, http.StatusNotFound, fmt.Sprintf("No order %s on %s %s", p["id"], p["venue"], p["stock"]))
+		return nil, false
+	}
+	return order, true
+}
+
+func (m *MockVenue) book(venue, stock string) *mockBook {
+	book, ok := m.books[venue+"/"+stock]
+	if !ok {
+		book = &mockBook{venue: venue, symbol: stock}
+		m.books[venue+"/"+stock] = book
+	}
+	return book
+}
+
+// match trades the incoming order against the other side of the book for as
+// long as it crosses, then rests what's left of a limit order and cancels
+// what's left of anything else.
+func (m *MockVenue) match(book *mockBook, order *OrderResultAlt) {
+	buy := order.Direction == Buy
+	against := &book.asks
+	if !buy {
+		against = &book.bids
+	}
+
+	crosses := func(resting *OrderResultAlt) bool {
+		switch {
+		case OrderType(order.Type) == Market:
+			return true
+		case buy:
+			return order.Price >= resting.Price
+		default:
+			return order.Price <= resting.Price
+		}
+	}
+
+	if OrderType(order.Type) == FillOrKill {
+		available := 0
+		for _, resting := range *against {
+			if !crosses(resting) {
+				break
+			}
+			available += resting.Qty
+		}
+		if available < order.Qty {
+			order.Qty = 0
+			order.Open = false
+			return
+		}
+	}
+
+	now := time.Now()
+	for order.Qty > 0 && len(*against) > 0 && crosses((*against)[0]) {
+		resting := (*against)[0]
+		qty := min(order.Qty, resting.Qty)
+		fill := Fill{Price: resting.Price, Qty: qty, Timestamp: now}
+
+		for _, o := range []*OrderResultAlt{order, resting} {
+			o.Fills = append(o.Fills, fill)
+			o.Qty -= qty
+			o.TotalFilled += qty
+		}
+
+		if resting.Qty == 0 {
+			resting.Open = false
+			*against = (*against)[1:]
+		}
+
+		book.last, book.lastSize, book.lastTrade = fill.Price, fill.Qty, now
+	}
+
+	if order.Qty == 0 {
+		order.Open = false
+		return
+	}
+
+	if OrderType(order.Type) != Limit {
+		order.Qty = 0
+		order.Open = false
+		return
+	}
+
+	// rest behind everything at the same price or better
+	side := &book.bids
+	if !buy {
+		side = &book.asks
+	}
+	k := sort.Search(len(*side), func(k int) bool {
+		if buy {
+			return (*side)[k].Price < order.Price
+		}
+		return (*side)[k].Price > order.Price
+	})
+	*side = append(*side, nil)
+	copy((*side)[k+1:], (*side)[k:])
+	(*side)[k] = order
+}
+
+// mockTouch is the best price on a side, the qty there and the qty on the
+// whole side.
+func mockTouch(orders []*OrderResultAlt) (price, size, depth int) {
+	for _, order := range orders {
+		if order.Price == orders[0].Price {
+			size += order.Qty
+		}
+		depth += order.Qty
+	}
+	if len(orders) > 0 {
+		price = orders[0].Price
+	}
+	return price, size, depth
+}
+
+func mockRemove(orders []*OrderResultAlt, order *OrderResultAlt) []*OrderResultAlt {
+	for k, o := range orders {
+		if o == order {
+			return append(orders[:k], orders[k+1:]...)
+		}
+	}
+	return orders
+}
+
+func mockReply(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(v)
+}
+
+func mockError(w http.ResponseWriter, code int, message string) {
+	mockReply(w, code, map[string]interface{}{"ok": false, "error": message})
+}
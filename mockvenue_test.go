@@ -0,0 +1,125 @@
+package starfighter
+
+import (
+	"context"
+	"testing"
+)
+
+func TestMockVenueMatching(t *testing.T) {
+	m := NewMockVenue()
+	defer m.Close()
+	c := m.NewClient()
+
+	place := func(account string, price, qty int64, direction Direction, orderType OrderType) *OrderResult {
+		t.Helper()
+		order, err := c.PlaceStockOrder(account, TestExchange, TestStock, price, qty, string(direction), string(orderType))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return order
+	}
+
+	// two asks at 101 (oldest first) and one at 102
+	first := place("SELLER", 101, 10, Sell, Limit)
+	second := place("SELLER", 101, 10, Sell, Limit)
+	third := place("SELLER", 102, 10, Sell, Limit)
+	place("BUYER", 99, 5, Buy, Limit)
+
+	quote, err := c.QuoteStock(TestExchange, TestStock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if quote.Bid != 99 || quote.Ask != 101 || quote.AskSize != 20 || quote.AskDepth != 30 {
+		t.Errorf("unexpected quote %+v", quote)
+	}
+
+	// crosses through the 101s into the 102
+	buy := place(TestAccount, 102, 25, Buy, Limit)
+	if buy.Open || buy.TotalFilled != 25 || len(buy.Fills) != 3 {
+		t.Fatalf("expected the buy to fill completely in 3 fills, got %+v", buy)
+	}
+	for k, price := range []int{101, 101, 102} {
+		if buy.Fills[k].Price != price {
+			t.Errorf("fill %d: expected price %d, got %d", k, price, buy.Fills[k].Price)
+		}
+	}
+
+	for _, tc := range []struct {
+		id     int
+		filled int
+		open   bool
+	}{{first.ID, 10, false}, {second.ID, 10, false}, {third.ID, 5, true}} {
+		status, err := c.GetOrderStatus(TestExchange, TestStock, int64(tc.id))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if status.TotalFilled != tc.filled || status.Open != tc.open {
+			t.Errorf("order %d: expected %d filled (open %v), got %+v", tc.id, tc.filled, tc.open, status)
+		}
+	}
+
+	book, err := c.GetStockOrderbook(TestExchange, TestStock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(book.Asks) != 1 || book.Asks[0].Price != 102 || book.Asks[0].Qty != 5 {
+		t.Errorf("expected 5 left at 102, got %+v", book.Asks)
+	}
+
+	quote, _ = c.QuoteStock(TestExchange, TestStock)
+	if quote.Last != 102 || quote.LastSize != 5 {
+		t.Errorf("expected the last trade to be 5 at 102, got %+v", quote)
+	}
+
+	// not enough there for a fill-or-kill, and an IOC takes what there is
+	fok := place(TestAccount, 105, 10, Buy, FillOrKill)
+	if fok.Open || fok.TotalFilled != 0 {
+		t.Errorf("expected the fill-or-kill to be killed, got %+v", fok)
+	}
+	ioc := place(TestAccount, 105, 10, Buy, ImmediateOrCancel)
+	if ioc.Open || ioc.TotalFilled != 5 {
+		t.Errorf("expected the IOC to fill 5 and cancel the rest, got %+v", ioc)
+	}
+
+	cancelled, err := c.CancelOrder(TestExchange, TestStock, int64(place("BUYER", 98, 5, Buy, Limit).ID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cancelled.Open {
+		t.Error("expected the cancelled order to be closed")
+	}
+
+	orders, err := c.ListVenueOrderStatus(TestExchange, "SELLER")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(orders.Orders) != 3 {
+		t.Errorf("expected the seller's 3 orders, got %d", len(orders.Orders))
+	}
+
+	if _, err := c.GetOrderStatus(TestExchange, TestStock, 1000); err == nil {
+		t.Error("expected an error for an unknown order")
+	}
+}
+
+func TestMockVenueReduceOrder(t *testing.T) {
+	m := NewMockVenue()
+	defer m.Close()
+	c := m.NewClient()
+
+	order, err := c.PlaceStockOrder(TestAccount, TestExchange, TestStock, 100, 50, string(Sell), string(Limit))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = c.PlaceStockOrder(TestAccount, TestExchange, TestStock, 100, 20, string(Buy), string(Limit)); err != nil {
+		t.Fatal(err)
+	}
+
+	replacement, filled, err := c.ReduceOrder(context.Background(), TestExchange, TestStock, int64(order.ID), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filled != 20 || replacement.Qty != 10 || replacement.Price != 100 || !replacement.Open {
+		t.Errorf("expected 20 filled and 10 resting at 100, got %d and %+v", filled, replacement)
+	}
+}
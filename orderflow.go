@@ -0,0 +1,81 @@
+package starfighter
+
+import "sync"
+
+// OrderFlowTracker keeps an exponentially weighted moving average of signed
+// trade volume: positive when buyers are the ones crossing the spread,
+// negative when sellers are. It makes a short-term momentum signal.
+// Feed it quotes and executions; it's safe to do so from several goroutines.
+type OrderFlowTracker struct {
+	mu     sync.Mutex
+	alpha  float64
+	signal float64
+	bid    int
+	ask    int
+}
+
+// NewOrderFlowTracker creates an OrderFlowTracker. alpha (0-1) is the weight
+// each new execution gets; higher reacts faster.
+func NewOrderFlowTracker(alpha float64) *OrderFlowTracker {
+	return &OrderFlowTracker{alpha: alpha}
+}
+
+// AddQuote updates the prevailing quote executions are classified against.
+func (t *OrderFlowTracker) AddQuote(quote StockQuote) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.bid, t.ask = quote.Bid, quote.Ask
+}
+
+// AddExecution works out which side started the trade and adds its volume
+// to the average. Trades at or through the ask are buys, at or through the
+// bid sells, and inside the spread whichever side of the midpoint they're
+// on. With no quote to go on, or a trade right at the midpoint, the incoming
+// order is taken to be the aggressor.
+func (t *OrderFlowTracker) AddExecution(execution Execution) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	volume := float64(execution.Filled)
+	if aggressor(t.bid, t.ask, execution) == Sell {
+		volume = -volume
+	}
+
+	t.signal = t.alpha*volume + (1-t.alpha)*t.signal
+}
+
+// Signal is the current average; its sign says who's been pushing.
+func (t *OrderFlowTracker) Signal() float64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.signal
+}
+
+// aggressor works out which side started the trade, given the quote before
+// it, as described for AddExecution.
+func aggressor(bid, ask int, execution Execution) Direction {
+	price := execution.Price
+
+	switch {
+	case ask > 0 && price >= ask:
+		return Buy
+	case bid > 0 && price <= bid:
+		return Sell
+	case ask > 0 && bid > 0 && 2*price > bid+ask:
+		return Buy
+	case ask > 0 && bid > 0 && 2*price < bid+ask:
+		return Sell
+	}
+
+	// the order in the execution is ours; if it wasn't the incoming one,
+	// the other side was
+	direction := execution.Order.Direction
+	if execution.Order.ID != execution.IncomingID {
+		if direction == Buy {
+			return Sell
+		}
+		return Buy
+	}
+	return direction
+}
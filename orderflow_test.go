@@ -0,0 +1,44 @@
+package starfighter
+
+import "testing"
+
+func TestOrderFlowTrackerBuyBurst(t *testing.T) {
+	tracker := NewOrderFlowTracker(0.5)
+	tracker.AddQuote(StockQuote{Bid: 100, Ask: 102})
+
+	tracker.AddExecution(Execution{Price: 100, Filled: 10})
+	if tracker.Signal() >= 0 {
+		t.Errorf("expected a sell at the bid to go negative, got %v", tracker.Signal())
+	}
+
+	for i := 0; i < 5; i++ {
+		tracker.AddExecution(Execution{Price: 102, Filled: 20})
+	}
+	if tracker.Signal() <= 0 {
+		t.Errorf("expected a burst of buys to go positive, got %v", tracker.Signal())
+	}
+}
+
+func TestOrderFlowTrackerAggressor(t *testing.T) {
+	tracker := NewOrderFlowTracker(1)
+
+	for _, c := range []struct {
+		bid, ask  int
+		execution Execution
+		signal    float64
+	}{
+		{100, 104, Execution{Price: 103, Filled: 5}, 5},
+		{100, 104, Execution{Price: 101, Filled: 5}, -5},
+		{100, 104, Execution{Price: 105, Filled: 5}, 5},
+		// no quote: our sell was resting, so a buyer hit it
+		{0, 0, Execution{Price: 101, Filled: 5, StandingID: 1, IncomingID: 2, Order: OrderResultAlt{ID: 1, Direction: "sell"}}, 5},
+		// at the midpoint: our sell was the incoming order
+		{100, 104, Execution{Price: 102, Filled: 5, StandingID: 1, IncomingID: 2, Order: OrderResultAlt{ID: 2, Direction: "sell"}}, -5},
+	} {
+		tracker.AddQuote(StockQuote{Bid: c.bid, Ask: c.ask})
+		tracker.AddExecution(c.execution)
+		if tracker.Signal() != c.signal {
+			t.Errorf("%+v: expected %v, got %v", c.execution, c.signal, tracker.Signal())
+		}
+	}
+}
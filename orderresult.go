@@ -0,0 +1,192 @@
+package starfighter
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// UnmarshalJSON decodes the order, making sure Fills is never nil even if
+// the API leaves it out or sends null.
+func (o *OrderResult) UnmarshalJSON(data []byte) error {
+	type orderResult OrderResult
+	if err := json.Unmarshal(data, (*orderResult)(o)); err != nil {
+		return err
+	}
+
+	if o.Fills == nil {
+		o.Fills = []Fill{}
+	}
+	return nil
+}
+
+// UnmarshalJSON decodes the order, making sure Fills is never nil even if
+// the API leaves it out or sends null.
+func (o *OrderResultAlt) UnmarshalJSON(data []byte) error {
+	type orderResultAlt OrderResultAlt
+	if err := json.Unmarshal(data, (*orderResultAlt)(o)); err != nil {
+		return err
+	}
+
+	if o.Fills == nil {
+		o.Fills = []Fill{}
+	}
+	return nil
+}
+
+// UnmarshalJSON decodes the list an order at a time, so that one the API
+// got wrong doesn't lose you the rest. The ones that couldn't be decoded are
+// left out and listed in Warnings.
+func (l *OrderResultList) UnmarshalJSON(data []byte) error {
+	raw := struct {
+		Orders []json.RawMessage `json:"orders"`
+	}{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	l.Orders, l.Warnings = decodeOrders(raw.Orders)
+	return nil
+}
+
+func decodeOrders(raw []json.RawMessage) ([]OrderResultAlt, []error) {
+	orders := make([]OrderResultAlt, 0, len(raw))
+	var warnings []error
+
+	for k, data := range raw {
+		order := OrderResultAlt{}
+		if err := json.Unmarshal(data, &order); err != nil {
+			warnings = append(warnings, fmt.Errorf("starfighter: skipped order %d of %d in list: %w", k+1, len(raw), err))
+			continue
+		}
+		orders = append(orders, order)
+	}
+
+	return orders, warnings
+}
+
+// ErrWrongAccount is what the warning says for an order in a list that's for
+// some other account than the one asked for.
+var ErrWrongAccount = errors.New("starfighter: order is for the wrong account")
+
+// dropOtherAccounts takes out orders that say they're for some account
+// other than account, which the API should never send but we'd rather not
+// trade off someone else's orders if it does, and adds a warning for each.
+func (l *OrderResultList) dropOtherAccounts(account string) {
+	orders := l.Orders[:0]
+	for _, order := range l.Orders {
+		if order.Account != "" && order.Account != account {
+			l.Warnings = append(l.Warnings, fmt.Errorf("%w: order %d is for %s, not %s", ErrWrongAccount, order.ID, order.Account, account))
+			continue
+		}
+		orders = append(orders, order)
+	}
+	l.Orders = orders
+}
+
+// VWAP is the volume-weighted average price of the order's fills.
+// It's false if nothing has filled.
+func (o *OrderResult) VWAP() (float64, bool) {
+	return vwap(o.Fills)
+}
+
+// VWAP is the volume-weighted average price of the order's fills.
+// It's false if nothing has filled.
+func (o *OrderResultAlt) VWAP() (float64, bool) {
+	return vwap(o.Fills)
+}
+
+func vwap(fills []Fill) (float64, bool) {
+	notional, qty := 0, 0
+	for _, fill := range fills {
+		notional += fill.Price * fill.Qty
+		qty += fill.Qty
+	}
+
+	if qty == 0 {
+		return 0, false
+	}
+	return float64(notional) / float64(qty), true
+}
+
+// RemainingQty is how much of the order is still waiting to fill. It's 0
+// once the order is closed, filled or not, since the API zeroes Qty then.
+func (o *OrderResultAlt) RemainingQty() int {
+	return o.Qty
+}
+
+// FilledFraction is how much of what was ordered has filled, from 0 to 1.
+// An order for nothing has nothing to fill, so it's 0.
+func (o *OrderResultAlt) FilledFraction() float64 {
+	if o.OriginalQty <= 0 {
+		return 0
+	}
+	return float64(o.TotalFilled) / float64(o.OriginalQty)
+}
+
+// Age is how long before now the order was placed, or AgeUnknown if it
+// doesn't say.
+func (o *OrderResultAlt) Age(now time.Time) time.Duration {
+	return age(o.Timestamp, now)
+}
+
+// StaleOrders picks out the open orders placed more than maxAge before now,
+// e.g. quotes that have been sitting there too long and should be
+// refreshed. Orders that don't say when they were placed count as stale.
+func StaleOrders(orders []OrderResultAlt, maxAge time.Duration, now time.Time) []OrderResultAlt {
+	stale := []OrderResultAlt{}
+	for _, order := range orders {
+		if order.Open && order.Age(now) > maxAge {
+			stale = append(stale, order)
+		}
+	}
+	return stale
+}
+
+// Dedup collapses orders the API has listed more than once (it does, now and
+// then) into one, so they don't get counted twice. Of the copies it keeps
+// the most filled, then the most recent. Orders stay in the order they were
+// first listed.
+func (l *OrderResultList) Dedup() {
+	seen := make(map[int]int, len(l.Orders))
+	orders := l.Orders[:0]
+
+	for _, order := range l.Orders {
+		k, ok := seen[order.ID]
+		if !ok {
+			seen[order.ID] = len(orders)
+			orders = append(orders, order)
+			continue
+		}
+
+		kept := orders[k]
+		if order.TotalFilled > kept.TotalFilled ||
+			(order.TotalFilled == kept.TotalFilled && order.Timestamp.After(kept.Timestamp)) {
+			orders[k] = order
+		}
+	}
+
+	l.Orders = orders
+}
+
+// FillRatioByPrice is, for each limit price in the list, the fraction of the
+// qty ordered at that price that filled, across all the orders there. For a
+// market maker it shows which prices actually trade. Orders for nothing
+// are left out.
+func (l *OrderResultList) FillRatioByPrice() map[int]float64 {
+	ordered, filled := map[int]int{}, map[int]int{}
+	for _, order := range l.Orders {
+		if order.OriginalQty <= 0 {
+			continue
+		}
+		ordered[order.Price] += order.OriginalQty
+		filled[order.Price] += order.TotalFilled
+	}
+
+	ratios := make(map[int]float64, len(ordered))
+	for price, qty := range ordered {
+		ratios[price] = float64(filled[price]) / float64(qty)
+	}
+	return ratios
+}
@@ -0,0 +1,232 @@
+package starfighter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOrderMissingFills(t *testing.T) {
+	for _, data := range []string{
+		`{"id": 1}`,
+		`{"id": 1, "fills": null}`,
+		`{"id": 1, "fills": []}`,
+	} {
+		order := OrderResult{}
+		if err := json.Unmarshal([]byte(data), &order); err != nil {
+			t.Fatal(err)
+		}
+		if order.Fills == nil || len(order.Fills) != 0 {
+			t.Errorf("%s: expected an empty slice, got %#v", data, order.Fills)
+		}
+		if price, ok := order.VWAP(); price != 0 || ok {
+			t.Errorf("%s: expected (0, false), got (%v, %v)", data, price, ok)
+		}
+
+		list := OrderResultList{}
+		if err := json.Unmarshal([]byte(`{"orders": [`+data+`]}`), &list); err != nil {
+			t.Fatal(err)
+		}
+		if list.Orders[0].Fills == nil || list.Orders[0].ID != 1 {
+			t.Errorf("%s: expected an empty slice, got %#v", data, list.Orders[0])
+		}
+		if price, ok := list.Orders[0].VWAP(); price != 0 || ok {
+			t.Errorf("%s: expected (0, false), got (%v, %v)", data, price, ok)
+		}
+	}
+}
+
+func TestOrderDirection(t *testing.T) {
+	for _, direction := range []Direction{Buy, Sell} {
+		data := fmt.Sprintf(`{"id": 1, "direction": %q}`, direction)
+
+		order := OrderResult{}
+		if err := json.Unmarshal([]byte(data), &order); err != nil {
+			t.Fatal(err)
+		}
+		alt := OrderResultAlt{}
+		if err := json.Unmarshal([]byte(data), &alt); err != nil {
+			t.Fatal(err)
+		}
+		if order.Direction != direction || alt.Direction != direction {
+			t.Errorf("%s: expected %s, got %s and %s", data, direction, order.Direction, alt.Direction)
+		}
+
+		// and back out the same way
+		out, err := json.Marshal(alt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(out), fmt.Sprintf(`"direction":%q`, direction)) {
+			t.Errorf("expected %s to have direction %s", out, direction)
+		}
+	}
+}
+
+func TestOrderVWAP(t *testing.T) {
+	order := OrderResult{Fills: []Fill{{Price: 100, Qty: 10}, {Price: 110, Qty: 30}}}
+	if price, ok := order.VWAP(); price != 107.5 || !ok {
+		t.Errorf("expected (107.5, true), got (%v, %v)", price, ok)
+	}
+}
+
+func TestOrderQtys(t *testing.T) {
+	for _, c := range []struct {
+		name      string
+		order     OrderResultAlt
+		remaining int
+		fraction  float64
+	}{
+		{"filled", OrderResultAlt{OriginalQty: 100, Qty: 0, TotalFilled: 100}, 0, 1},
+		{"partial", OrderResultAlt{OriginalQty: 100, Qty: 60, TotalFilled: 40, Open: true}, 60, 0.4},
+		{"unfilled", OrderResultAlt{OriginalQty: 100, Qty: 100, Open: true}, 100, 0},
+		{"cancelled", OrderResultAlt{OriginalQty: 100, Qty: 0, TotalFilled: 25}, 0, 0.25},
+		{"empty", OrderResultAlt{}, 0, 0},
+	} {
+		if remaining := c.order.RemainingQty(); remaining != c.remaining {
+			t.Errorf("%s: expected %d remaining, got %d", c.name, c.remaining, remaining)
+		}
+		if fraction := c.order.FilledFraction(); fraction != c.fraction {
+			t.Errorf("%s: expected %v filled, got %v", c.name, c.fraction, fraction)
+		}
+	}
+}
+
+func TestStaleOrders(t *testing.T) {
+	now := time.Date(2016, 1, 1, 12, 0, 0, 0, time.UTC)
+	orders := []OrderResultAlt{
+		{ID: 1, Open: true, Timestamp: now.Add(-10 * time.Second)},
+		{ID: 2, Open: true, Timestamp: now.Add(-time.Minute)},
+		{ID: 3, Open: false, Timestamp: now.Add(-time.Hour)},
+		{ID: 4, Open: true, Timestamp: now.Add(-30 * time.Second)},
+		{ID: 5, Open: true},
+	}
+
+	if age := orders[1].Age(now); age != time.Minute {
+		t.Errorf("expected order 2 to be a minute old, got %v", age)
+	}
+
+	ids := []int{}
+	for _, order := range StaleOrders(orders, 30*time.Second, now) {
+		ids = append(ids, order.ID)
+	}
+	if fmt.Sprint(ids) != "[2 5]" {
+		t.Errorf("expected orders [2 5] to be stale, got %v", ids)
+	}
+}
+
+func TestOrderResultListDedup(t *testing.T) {
+	list := OrderResultList{}
+	err := json.Unmarshal([]byte(`{"orders": [
+		{"id": 1, "totalFilled": 5, "ts": "2016-01-01T12:00:00Z"},
+		{"id": 2, "totalFilled": 0, "ts": "2016-01-01T12:00:01Z"},
+		{"id": 1, "totalFilled": 10, "ts": "2016-01-01T12:00:02Z"},
+		{"id": 1, "totalFilled": 7, "ts": "2016-01-01T12:00:03Z"},
+		{"id": 3, "totalFilled": 0, "qty": 10, "ts": "2016-01-01T12:00:04Z"},
+		{"id": 3, "totalFilled": 0, "qty": 9, "ts": "2016-01-01T12:00:05Z"}
+	]}`), &list)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	list.Dedup()
+
+	if len(list.Orders) != 3 {
+		t.Fatalf("expected 3 orders, got %d", len(list.Orders))
+	}
+	for k, id := range []int{1, 2, 3} {
+		if list.Orders[k].ID != id {
+			t.Errorf("position %d: expected order %d, got %d", k, id, list.Orders[k].ID)
+		}
+	}
+	if list.Orders[0].TotalFilled != 10 {
+		t.Errorf("expected the most filled copy of order 1, got %d filled", list.Orders[0].TotalFilled)
+	}
+	if list.Orders[2].Qty != 9 {
+		t.Errorf("expected the latest copy of order 3, got qty %d", list.Orders[2].Qty)
+	}
+}
+
+func TestOrderResultListMalformedEntry(t *testing.T) {
+	body := `{"ok": true, "orders": [
+		{"id": 1, "qty": 10},
+		{"id": "two", "qty": 10},
+		{"id": 3, "qty": 30}
+	]}`
+	c, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, body)
+	})
+	defer server.Close()
+
+	buffered, err := c.ListVenueOrderStatus(TestExchange, TestAccount)
+	if err != nil {
+		t.Fatal(err)
+	}
+	streamed, err := c.ListVenueOrderStatusUnbuffered(context.Background(), TestExchange, TestAccount)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, list := range []*OrderResultList{buffered, streamed} {
+		if len(list.Orders) != 2 || list.Orders[0].ID != 1 || list.Orders[1].ID != 3 {
+			t.Errorf("expected orders 1 and 3, got %+v", list.Orders)
+		}
+		if len(list.Warnings) != 1 || !strings.Contains(list.Warnings[0].Error(), "order 2 of 3") {
+			t.Errorf("expected a warning about the second order, got %v", list.Warnings)
+		}
+	}
+}
+
+func TestOrderResultListWrongAccount(t *testing.T) {
+	c, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"ok": true, "orders": [
+			{"id": 1, "account": %q},
+			{"id": 2, "account": "SOMEONEELSE"},
+			{"id": 3, "account": %q}
+		]}`, TestAccount, TestAccount)
+	})
+	defer server.Close()
+
+	buffered, err := c.ListVenueOrderStatus(TestExchange, TestAccount)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stock, err := c.ListVenueStockOrderStatus(TestExchange, TestStock, TestAccount)
+	if err != nil {
+		t.Fatal(err)
+	}
+	streamed, err := c.ListVenueOrderStatusUnbuffered(context.Background(), TestExchange, TestAccount)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, list := range []*OrderResultList{buffered, stock, streamed} {
+		if len(list.Orders) != 2 || list.Orders[0].ID != 1 || list.Orders[1].ID != 3 {
+			t.Errorf("expected orders 1 and 3, got %+v", list.Orders)
+		}
+		if len(list.Warnings) != 1 || !errors.Is(list.Warnings[0], ErrWrongAccount) {
+			t.Errorf("expected a wrong account warning, got %v", list.Warnings)
+		}
+	}
+}
+
+func TestFillRatioByPrice(t *testing.T) {
+	list := &OrderResultList{Orders: []OrderResultAlt{
+		{Price: 100, OriginalQty: 10, TotalFilled: 10},
+		{Price: 100, OriginalQty: 30, TotalFilled: 20},
+		{Price: 99, OriginalQty: 50, TotalFilled: 5},
+		{Price: 99, OriginalQty: 50, TotalFilled: 0},
+		{Price: 98},
+	}}
+
+	expected := map[int]float64{100: 0.75, 99: 0.05}
+	if ratios := list.FillRatioByPrice(); !reflect.DeepEqual(ratios, expected) {
+		t.Errorf("expected %v, got %v", expected, ratios)
+	}
+}
@@ -0,0 +1,483 @@
+package starfighter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// batchConcurrency is how many requests batch helpers have in flight at once.
+const batchConcurrency = 8
+
+var (
+	// ErrNoLiquidity is returned when there's nothing on the side of the book
+	// an order needs to trade against.
+	ErrNoLiquidity = errors.New("starfighter: no liquidity on that side of the book")
+	// ErrNothingToPeg is returned when there's nothing on the side of the book
+	// a pegged order should be priced from.
+	ErrNothingToPeg = errors.New("starfighter: nothing on that side of the book to peg to")
+	// ErrNoEdge is returned when the spread is too tight to quote into.
+	ErrNoEdge = errors.New("starfighter: spread is too tight for the edge wanted")
+	// ErrWouldCross is returned when a post-only order would trade against
+	// the book rather than rest on it.
+	ErrWouldCross = errors.New("starfighter: order would cross the book")
+	// ErrNotReduced is returned when an order asked to be reduced doesn't
+	// have more than the new qty left to fill.
+	ErrNotReduced = errors.New("starfighter: order has no more than that left to fill")
+	// ErrNotReplaced is returned when an order was cancelled to be replaced,
+	// but the replacement couldn't be placed, so there's nothing resting.
+	ErrNotReplaced = errors.New("starfighter: order was cancelled but not replaced")
+)
+
+// FlattenPosition works out the account's net position in the stock from its
+// order history and sends an immediate-or-cancel order that sweeps the book
+// to bring it back to zero. If the position is already flat it does nothing
+// and returns nil. The book may not be deep enough to flatten completely, so
+// check the result.
+func (c *Client) FlattenPosition(ctx context.Context, account, venue, stock string) (*OrderResult, error) {
+	orders, err := c.listVenueStockOrderStatus(ctx, venue, stock, account)
+	if err != nil {
+		return nil, err
+	}
+
+	position := NetPosition(orders.Orders)
+	if position == 0 {
+		return nil, nil
+	}
+
+	book, err := c.getStockOrderbook(ctx, venue, stock)
+	if err != nil {
+		return nil, err
+	}
+
+	direction := Sell
+	if position < 0 {
+		direction, position = Buy, -position
+	}
+
+	price, err := sweepPrice(book, direction)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.placeStockOrder(ctx, account, venue, stock, int64(price), int64(position), string(direction), string(ImmediateOrCancel))
+}
+
+// RunStopLoss watches the stock's last trade price until it reaches
+// stopPrice (falls to it for a Sell, rises to it for a Buy), then sends an
+// immediate-or-cancel order for qty in direction dir that sweeps the book.
+// It polls every PollInterval, fires at most once and returns when the
+// order is placed, or when ctx is done.
+func (c *Client) RunStopLoss(ctx context.Context, account, venue, stock string, stopPrice int64, dir Direction, qty int64) error {
+	breached := func(quote *StockQuote) bool {
+		if quote.Last == 0 {
+			// nothing's traded yet
+			return false
+		}
+		if dir == Sell {
+			return int64(quote.Last) <= stopPrice
+		}
+		return int64(quote.Last) >= stopPrice
+	}
+
+	if _, err := c.WaitForPrice(ctx, venue, stock, breached, c.pollInterval()); err != nil {
+		return err
+	}
+
+	book, err := c.getStockOrderbook(ctx, venue, stock)
+	if err != nil {
+		return err
+	}
+
+	price, err := sweepPrice(book, dir)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.placeStockOrder(ctx, account, venue, stock, int64(price), qty, string(dir), string(ImmediateOrCancel))
+	return err
+}
+
+// sweepPrice is the price at the far end of the side of the book an order
+// in direction trades against, so that it can take all of it.
+func sweepPrice(book *OrderBook, direction Direction) (int, error) {
+	entries := book.against(direction)
+	if len(entries) == 0 {
+		return 0, ErrNoLiquidity
+	}
+
+	return entries[len(entries)-1].Price, nil
+}
+
+// CancelOrders cancels the orders all at once (well, a few at a time).
+// The results and errors line up with orderIDs; where an error is set the
+// result is empty.
+func (c *Client) CancelOrders(ctx context.Context, venue, stock string, orderIDs []int64) ([]OrderResultAlt, []error) {
+	results := make([]OrderResultAlt, len(orderIDs))
+	errs := make([]error, len(orderIDs))
+
+	slots := make(chan struct{}, batchConcurrency)
+	var wg sync.WaitGroup
+
+	for k, id := range orderIDs {
+		wg.Add(1)
+		go func(k int, id int64) {
+			defer wg.Done()
+
+			select {
+			case slots <- struct{}{}:
+				defer func() { <-slots }()
+			case <-ctx.Done():
+				errs[k] = ctx.Err()
+				return
+			}
+
+			result, err := c.cancelOrder(ctx, venue, stock, id)
+			if err != nil {
+				errs[k] = err
+				return
+			}
+			results[k] = *result
+		}(k, id)
+	}
+
+	wg.Wait()
+
+	return results, errs
+}
+
+// CancelAllForStock cancels every open order the account has in the stock,
+// leaving its orders in other stocks alone. It returns what the cancels
+// came back with, and everything that went wrong together.
+func (c *Client) CancelAllForStock(ctx context.Context, venue, stock, account string) ([]OrderResultAlt, error) {
+	orders, err := c.listVenueStockOrderStatus(ctx, venue, stock, account)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := []int64{}
+	for _, order := range orders.Orders {
+		if order.Open {
+			ids = append(ids, int64(order.ID))
+		}
+	}
+
+	results, cancelErrs := c.CancelOrders(ctx, venue, stock, ids)
+
+	cancelled := []OrderResultAlt{}
+	var errs []error
+	for k, err := range cancelErrs {
+		if err != nil {
+			errs = append(errs, fmt.Errorf("starfighter: cancelling order %d: %w", ids[k], err))
+			continue
+		}
+		cancelled = append(cancelled, results[k])
+	}
+
+	return cancelled, errors.Join(errs...)
+}
+
+// CancelAndConfirm cancels the order, then checks its status every poll
+// (PollInterval if poll is zero or less) until it's closed, because the
+// cancel doesn't always take right away and the order can still show as
+// open for a bit. It gives up when ctx is done.
+func (c *Client) CancelAndConfirm(ctx context.Context, venue, stock string, orderID int64, poll time.Duration) (*OrderResultAlt, error) {
+	if poll <= 0 {
+		poll = c.pollInterval()
+	}
+
+	result, err := c.cancelOrder(ctx, venue, stock, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	// an empty response doesn't tell us anything, so check
+	for result.ID == 0 || result.Open {
+		if !sleep(ctx, poll) {
+			return nil, ctx.Err()
+		}
+
+		if result, err = c.getOrderStatus(ctx, venue, stock, orderID); err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// PlacePegged places a limit order priced off the current touch on its own
+// side of the book: the best bid plus offset for a buy, the best ask plus
+// offset for a sell. So a positive offset steps in front of the bids but
+// behind the asks.
+func (c *Client) PlacePegged(ctx context.Context, account, venue, stock string, side Direction, qty int64, offset int) (*OrderResult, error) {
+	book, err := c.getStockOrderbook(ctx, venue, stock)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := book.sorted(side)
+	if len(entries) == 0 {
+		return nil, ErrNothingToPeg
+	}
+
+	price := int64(entries[0].Price + offset)
+
+	return c.placeStockOrder(ctx, account, venue, stock, price, qty, string(side), string(Limit))
+}
+
+// PlacePeggedWithEdge is PlacePegged at the touch, but only if the spread
+// leaves at least minEdgeCents between the peg and the best price on the
+// other side; otherwise nothing is placed and it returns ErrNoEdge. That
+// keeps a market maker from quoting into a market too tight to make
+// anything in. With nothing on the other side, there's edge enough.
+func (c *Client) PlacePeggedWithEdge(ctx context.Context, account, venue, stock string, side Direction, qty int64, minEdgeCents int) (*OrderResult, error) {
+	book, err := c.getStockOrderbook(ctx, venue, stock)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := book.sorted(side)
+	if len(entries) == 0 {
+		return nil, ErrNothingToPeg
+	}
+	price := entries[0].Price
+
+	if opposite := book.against(side); len(opposite) > 0 {
+		edge := opposite[0].Price - price
+		if side == Sell {
+			edge = -edge
+		}
+		if edge < minEdgeCents {
+			return nil, fmt.Errorf("%w: %d cents to the other side, wanted %d", ErrNoEdge, edge, minEdgeCents)
+		}
+	}
+
+	return c.placeStockOrder(ctx, account, venue, stock, int64(price), qty, string(side), string(Limit))
+}
+
+// PlacePostOnly places a limit order only if it would rest on the book,
+// since the API doesn't do post-only orders itself. If the price reaches
+// the other side (the best ask or above, for a buy) it returns
+// ErrWouldCross instead. The book can move between checking it and the
+// order getting there, so it's a best effort.
+func (c *Client) PlacePostOnly(ctx context.Context, account, venue, stock string, price, qty int64, dir Direction) (*OrderResult, error) {
+	book, err := c.getStockOrderbook(ctx, venue, stock)
+	if err != nil {
+		return nil, err
+	}
+
+	if opposite := book.against(dir); len(opposite) > 0 {
+		best := int64(opposite[0].Price)
+		if (dir == Buy && price >= best) || (dir == Sell && price <= best) {
+			return nil, fmt.Errorf("%w: %s at %d against %d", ErrWouldCross, dir, price, best)
+		}
+	}
+
+	return c.placeStockOrder(ctx, account, venue, stock, price, qty, string(dir), string(Limit))
+}
+
+// PlaceWithTTL places a good-till-time order: if it's still open ttl after
+// it was placed, it's cancelled. Until then its status is checked every
+// PollInterval, and once it's seen to close (e.g. filled) nothing more is
+// done. The cancel happens in the background, after this has returned, and
+// is logged if it fails. ctx only covers placing the order: the order is
+// still watched and cancelled once ctx is done, until the client is Shut
+// down.
+func (c *Client) PlaceWithTTL(ctx context.Context, account, venue, stock string, price, qty int64, dir Direction, otype OrderType, ttl time.Duration) (*OrderResult, error) {
+	result, err := c.placeStockOrder(ctx, account, venue, stock, price, qty, string(dir), string(otype))
+	if err != nil || !result.Open {
+		return result, err
+	}
+
+	expired := c.clock().After(ttl)
+	id := int64(result.ID)
+	ctx, untrack := c.trackFeed(context.WithoutCancel(ctx))
+
+	go func() {
+		defer untrack()
+
+		// the status checks run on their own, so a slow one can't hold up
+		// the cancel
+		polls, stop := context.WithCancel(ctx)
+		defer stop()
+		closed := make(chan bool, 1)
+		next := c.clock().After(c.pollInterval())
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-expired:
+				stop()
+				if _, err := c.cancelOrder(ctx, venue, stock, id); err != nil {
+					c.logf("starfighter: cancelling order %d after %v: %v", id, ttl, err)
+				}
+				return
+			case <-next:
+				next = nil
+				go func() {
+					status, err := c.getOrderStatus(polls, venue, stock, id)
+					closed <- err == nil && status.ID != 0 && !status.Open
+				}()
+			case done := <-closed:
+				if done {
+					return
+				}
+				next = c.clock().After(c.pollInterval())
+			}
+		}
+	}()
+
+	return result, nil
+}
+
+// ReduceOrder shrinks a resting order to newQty shares still to fill. The
+// API can't modify orders, so it cancels the order and places a new one for
+// the rest at the same price, right after, to lose as little of its place in
+// the queue as it can (which is still all of it). The order's status is
+// checked first, and if it doesn't have more than newQty left, it's left
+// alone and ErrNotReduced is returned. If the order filled down to less
+// than newQty before the cancel, the new one is for what was left; if
+// nothing was left, there's no new order and the result is nil. filled is
+// how much of the cancelled order had been filled. If the cancel went
+// through but the new order couldn't be placed, the error is an
+// ErrNotReplaced wrapping why.
+func (c *Client) ReduceOrder(ctx context.Context, venue, stock string, orderID int64, newQty int64) (replacement *OrderResult, filled int, err error) {
+	if newQty <= 0 {
+		return nil, 0, fmt.Errorf("starfighter: can't reduce an order to %d shares", newQty)
+	}
+
+	status, err := c.getOrderStatus(ctx, venue, stock, orderID)
+	if err != nil {
+		return nil, 0, err
+	}
+	if !status.Open || newQty >= int64(status.Qty) {
+		return nil, status.TotalFilled, fmt.Errorf("%w: order %d has %d left, asked for %d", ErrNotReduced, orderID, status.Qty, newQty)
+	}
+
+	cancelled, err := c.cancelOrder(ctx, venue, stock, orderID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// a cancel that comes back empty doesn't say what was left, so ask
+	if cancelled.ID == 0 {
+		if cancelled, err = c.getOrderStatus(ctx, venue, stock, orderID); err != nil {
+			return nil, status.TotalFilled, fmt.Errorf("%w: order %d: %w", ErrNotReplaced, orderID, err)
+		}
+	}
+
+	// a cancelled order's qty is zeroed, so work out what was left
+	left := cancelled.Qty
+	if cancelled.OriginalQty > 0 {
+		left = cancelled.OriginalQty - cancelled.TotalFilled
+	}
+
+	qty := min(newQty, int64(left))
+	if qty <= 0 {
+		return nil, cancelled.TotalFilled, nil
+	}
+
+	replacement, err = c.placeStockOrder(ctx, cancelled.Account, venue, stock, int64(cancelled.Price), qty, string(cancelled.Direction), cancelled.Type)
+	if err != nil {
+		return nil, cancelled.TotalFilled, fmt.Errorf("%w: order %d: %w", ErrNotReplaced, orderID, err)
+	}
+	return replacement, cancelled.TotalFilled, nil
+}
+
+// PlaceLadder places levels bids below centerPrice and levels asks above
+// it, step cents apart, each for qty: a market maker's grid. The orders go
+// out at the same time. The results come back bids first, then asks, each
+// nearest the center first. If some orders fail, the ones that went through
+// are returned along with what went wrong.
+func (c *Client) PlaceLadder(ctx context.Context, account, venue, stock string, centerPrice, step, qty int64, levels int) ([]OrderResult, error) {
+	return c.placeLadder(ctx, account, venue, stock, centerPrice, step, qty, levels, false)
+}
+
+// PlaceLadderAllOrNone is PlaceLadder, except that if any order fails, the
+// ones that went through are cancelled again and nothing is returned.
+func (c *Client) PlaceLadderAllOrNone(ctx context.Context, account, venue, stock string, centerPrice, step, qty int64, levels int) ([]OrderResult, error) {
+	return c.placeLadder(ctx, account, venue, stock, centerPrice, step, qty, levels, true)
+}
+
+func (c *Client) placeLadder(ctx context.Context, account, venue, stock string, centerPrice, step, qty int64, levels int, rollback bool) ([]OrderResult, error) {
+	if levels <= 0 || step <= 0 {
+		return nil, fmt.Errorf("starfighter: bad ladder of %d levels %d apart", levels, step)
+	}
+	if centerPrice-step*int64(levels) <= 0 {
+		return nil, fmt.Errorf("starfighter: ladder around %d goes below zero", centerPrice)
+	}
+
+	type rung struct {
+		price     int64
+		direction Direction
+	}
+	rungs := make([]rung, 0, 2*levels)
+	for k := 1; k <= levels; k++ {
+		rungs = append(rungs, rung{centerPrice - step*int64(k), Buy})
+	}
+	for k := 1; k <= levels; k++ {
+		rungs = append(rungs, rung{centerPrice + step*int64(k), Sell})
+	}
+
+	results := make([]*OrderResult, len(rungs))
+	errs := make([]error, len(rungs))
+
+	slots := make(chan struct{}, batchConcurrency)
+	var wg sync.WaitGroup
+
+	for k, r := range rungs {
+		wg.Add(1)
+		go func(k int, r rung) {
+			defer wg.Done()
+
+			select {
+			case slots <- struct{}{}:
+				defer func() { <-slots }()
+			case <-ctx.Done():
+				errs[k] = ctx.Err()
+				return
+			}
+
+			results[k], errs[k] = c.placeStockOrder(ctx, account, venue, stock, r.price, qty, string(r.direction), string(Limit))
+		}(k, r)
+	}
+
+	wg.Wait()
+
+	placed := []OrderResult{}
+	failed := []error{}
+	for k, result := range results {
+		if errs[k] != nil {
+			failed = append(failed, fmt.Errorf("starfighter: %s at %d: %w", rungs[k].direction, rungs[k].price, errs[k]))
+			continue
+		}
+		placed = append(placed, *result)
+	}
+
+	if len(failed) == 0 {
+		return placed, nil
+	}
+	if !rollback {
+		return placed, errors.Join(failed...)
+	}
+
+	ids := make([]int64, len(placed))
+	for k, order := range placed {
+		ids[k] = int64(order.ID)
+	}
+	// roll back even if ctx is what went wrong
+	_, cancelErrs := c.CancelOrders(context.WithoutCancel(ctx), venue, stock, ids)
+	for k, err := range cancelErrs {
+		if err != nil {
+			failed = append(failed, fmt.Errorf("starfighter: rolling back order %d: %w", ids[k], err))
+		}
+	}
+
+	return nil, errors.Join(failed...)
+}
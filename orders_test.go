@@ -0,0 +1,655 @@
+package starfighter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFlattenPositionLong(t *testing.T) {
+	placed := map[string]interface{}{}
+
+	c, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case fmt.Sprintf("/venues/%s/accounts/%s/stocks/%s/orders", TestExchange, TestAccount, TestStock):
+			fmt.Fprint(w, `{"ok": true, "orders": [
+				{"id": 1, "direction": "buy", "totalFilled": 100},
+				{"id": 2, "direction": "sell", "totalFilled": 30},
+				{"id": 3, "direction": "buy", "totalFilled": 0, "open": true}
+			]}`)
+		case fmt.Sprintf("/venues/%s/stocks/%s", TestExchange, TestStock):
+			fmt.Fprint(w, `{"ok": true, "bids": [{"price": 5000, "qty": 50}, {"price": 4900, "qty": 50}], "asks": []}`)
+		case fmt.Sprintf("/venues/%s/stocks/%s/orders", TestExchange, TestStock):
+			json.NewDecoder(r.Body).Decode(&placed)
+			fmt.Fprint(w, `{"ok": true, "id": 4, "direction": "sell", "qty": 0, "totalFilled": 70}`)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	defer server.Close()
+
+	result, err := c.FlattenPosition(context.Background(), TestAccount, TestExchange, TestStock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result == nil || result.ID != 4 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+
+	if placed["direction"] != "sell" || placed["qty"] != 70.0 || placed["price"] != 4900.0 || placed["orderType"] != "immediate-or-cancel" {
+		t.Errorf("unexpected order placed: %v", placed)
+	}
+}
+
+func TestFlattenPositionFlat(t *testing.T) {
+	c, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" {
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+		fmt.Fprint(w, `{"ok": true, "orders": [
+			{"id": 1, "direction": "buy", "totalFilled": 10},
+			{"id": 2, "direction": "sell", "totalFilled": 10}
+		]}`)
+	})
+	defer server.Close()
+
+	result, err := c.FlattenPosition(context.Background(), TestAccount, TestExchange, TestStock)
+	if err != nil || result != nil {
+		t.Errorf("expected nothing to happen, got %+v, %v", result, err)
+	}
+}
+
+func TestRunStopLoss(t *testing.T) {
+	prices := []int{0, 120, 110, 100, 90}
+	quotes := int64(0)
+	orders := []map[string]interface{}{}
+
+	c, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case fmt.Sprintf("/venues/%s/stocks/%s/quote", TestExchange, TestStock):
+			n := atomic.AddInt64(&quotes, 1) - 1
+			if int(n) >= len(prices) {
+				n = int64(len(prices) - 1)
+			}
+			fmt.Fprintf(w, `{"ok": true, "last": %d}`, prices[n])
+		case fmt.Sprintf("/venues/%s/stocks/%s", TestExchange, TestStock):
+			fmt.Fprint(w, `{"ok": true, "bids": [{"price": 98, "qty": 20}, {"price": 95, "qty": 100}]}`)
+		case fmt.Sprintf("/venues/%s/stocks/%s/orders", TestExchange, TestStock):
+			order := map[string]interface{}{}
+			json.NewDecoder(r.Body).Decode(&order)
+			orders = append(orders, order)
+			fmt.Fprint(w, `{"ok": true, "id": 1}`)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	defer server.Close()
+	c.PollInterval = time.Millisecond
+
+	err := c.RunStopLoss(context.Background(), TestAccount, TestExchange, TestStock, 105, Sell, 50)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(orders) != 1 {
+		t.Fatalf("expected exactly one order, got %d", len(orders))
+	}
+	if quotes != 4 {
+		t.Errorf("expected to stop watching at the breach (4 quotes), got %d", quotes)
+	}
+
+	order := orders[0]
+	if order["direction"] != "sell" || order["qty"] != 50.0 || order["price"] != 95.0 || order["orderType"] != "immediate-or-cancel" {
+		t.Errorf("unexpected order placed: %v", order)
+	}
+}
+
+func TestCancelOrders(t *testing.T) {
+	c, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "DELETE" {
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+
+		var id int
+		fmt.Sscanf(r.URL.Path, "/venues/"+TestExchange+"/stocks/"+TestStock+"/orders/%d", &id)
+		if id == 2 {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"ok": false, "error": "Not your order"}`)
+			return
+		}
+		fmt.Fprintf(w, `{"ok": true, "id": %d, "open": false}`, id)
+	})
+	defer server.Close()
+	c.StatusRetries = -1
+
+	results, errs := c.CancelOrders(context.Background(), TestExchange, TestStock, []int64{1, 2, 3})
+	if len(results) != 3 || len(errs) != 3 {
+		t.Fatalf("expected 3 results and errors, got %d and %d", len(results), len(errs))
+	}
+
+	for k, id := range []int{1, 0, 3} {
+		if results[k].ID != id {
+			t.Errorf("result %d: expected order %d, got %+v", k, id, results[k])
+		}
+		if (errs[k] != nil) != (k == 1) {
+			t.Errorf("result %d: unexpected error %v", k, errs[k])
+		}
+	}
+}
+
+func TestCancelAndConfirm(t *testing.T) {
+	var statuses int32
+	c, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "DELETE":
+			fmt.Fprint(w, `{"ok": true, "id": 7, "open": true}`)
+		case "GET":
+			// still open the first time we look
+			open := atomic.AddInt32(&statuses, 1) == 1
+			fmt.Fprintf(w, `{"ok": true, "id": 7, "open": %v}`, open)
+		}
+	})
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	result, err := c.CancelAndConfirm(ctx, TestExchange, TestStock, 7, time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Open || atomic.LoadInt32(&statuses) != 2 {
+		t.Errorf("expected it closed after 2 status checks, got %+v after %d", result, statuses)
+	}
+
+	// never closes
+	c, server = newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"ok": true, "id": 7, "open": true}`)
+	})
+	defer server.Close()
+
+	ctx, cancel = context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if _, err := c.CancelAndConfirm(ctx, TestExchange, TestStock, 7, time.Millisecond); err != context.DeadlineExceeded {
+		t.Errorf("expected the deadline to be exceeded, got %v", err)
+	}
+
+	// no poll given goes by PollInterval, not as fast as it can
+	statuses = 0
+	c, server = newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "GET" {
+			atomic.AddInt32(&statuses, 1)
+		}
+		fmt.Fprint(w, `{"ok": true, "id": 7, "open": true}`)
+	})
+	defer server.Close()
+	c.PollInterval = 20 * time.Millisecond
+
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if _, err := c.CancelAndConfirm(ctx, TestExchange, TestStock, 7, 0); err != context.DeadlineExceeded {
+		t.Errorf("expected the deadline to be exceeded, got %v", err)
+	}
+	if n := atomic.LoadInt32(&statuses); n > 3 {
+		t.Errorf("expected a status check every 20ms, got %d in 50ms", n)
+	}
+}
+
+func TestPlacePeggedWithEdge(t *testing.T) {
+	var placed []orderRequest
+	book := testBook()
+	c, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "POST" {
+			order := orderRequest{}
+			json.NewDecoder(r.Body).Decode(&order)
+			placed = append(placed, order)
+			fmt.Fprint(w, `{"ok": true, "id": 1, "open": true}`)
+			return
+		}
+		json.NewEncoder(w).Encode(book)
+	})
+	defer server.Close()
+
+	// 100 bid, 102 asked: 2 cents of edge
+	for _, side := range []Direction{Buy, Sell} {
+		if _, err := c.PlacePeggedWithEdge(context.Background(), TestAccount, TestExchange, TestStock, side, 10, 2); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(placed) != 2 || placed[0].Price != 100 || placed[1].Price != 102 {
+		t.Errorf("expected a bid at 100 and an ask at 102, got %+v", placed)
+	}
+
+	// tightened to 101 bid: too tight for 2 cents
+	book.Bids = append(book.Bids, BookEntry{IsBuy: true, Price: 101, Qty: 5})
+	placed = nil
+	for _, side := range []Direction{Buy, Sell} {
+		if _, err := c.PlacePeggedWithEdge(context.Background(), TestAccount, TestExchange, TestStock, side, 10, 2); !errors.Is(err, ErrNoEdge) {
+			t.Errorf("%s: expected ErrNoEdge, got %v", side, err)
+		}
+	}
+	if len(placed) != 0 {
+		t.Errorf("expected nothing placed, got %+v", placed)
+	}
+}
+
+func TestPlacePostOnly(t *testing.T) {
+	var placed []orderRequest
+	c, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "POST" {
+			order := orderRequest{}
+			json.NewDecoder(r.Body).Decode(&order)
+			placed = append(placed, order)
+			fmt.Fprint(w, `{"ok": true, "id": 1, "open": true}`)
+			return
+		}
+		json.NewEncoder(w).Encode(testBook())
+	})
+	defer server.Close()
+
+	for _, tc := range []struct {
+		dir     Direction
+		price   int64
+		crosses bool
+	}{
+		{Buy, 102, true},
+		{Buy, 110, true},
+		{Buy, 101, false},
+		{Sell, 100, true},
+		{Sell, 101, false},
+	} {
+		placed = nil
+		_, err := c.PlacePostOnly(context.Background(), TestAccount, TestExchange, TestStock, tc.price, 10, tc.dir)
+		if tc.crosses {
+			if !errors.Is(err, ErrWouldCross) || len(placed) != 0 {
+				t.Errorf("%s @ %d: expected ErrWouldCross and nothing placed, got %v and %+v", tc.dir, tc.price, err, placed)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(placed) != 1 || placed[0].Price != tc.price || placed[0].OrderType != string(Limit) {
+			t.Errorf("%s @ %d: expected a limit order, got %+v", tc.dir, tc.price, placed)
+		}
+	}
+}
+
+func TestPlaceWithTTL(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		fills     bool
+		cancelled bool
+	}{
+		{"unfilled", false, true},
+		{"filled", true, false},
+	} {
+		cancelled := make(chan struct{}, 1)
+		c, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case "POST":
+				fmt.Fprint(w, `{"ok": true, "id": 7, "open": true}`)
+			case "GET":
+				fmt.Fprintf(w, `{"ok": true, "id": 7, "open": %v}`, !tc.fills)
+			case "DELETE":
+				cancelled <- struct{}{}
+				fmt.Fprint(w, `{"ok": true, "id": 7, "open": false}`)
+			}
+		})
+		c.PollInterval = 5 * time.Millisecond
+
+		start := time.Now()
+		order, err := c.PlaceWithTTL(context.Background(), TestAccount, TestExchange, TestStock, 100, 10, Buy, Limit, 50*time.Millisecond)
+		if err != nil || order.ID != 7 {
+			t.Fatalf("%s: expected order 7, got %+v (%v)", tc.name, order, err)
+		}
+
+		select {
+		case <-cancelled:
+			if !tc.cancelled {
+				t.Errorf("%s: expected no cancel", tc.name)
+			} else if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+				t.Errorf("%s: expected the cancel after the TTL, came after %v", tc.name, elapsed)
+			}
+		case <-time.After(200 * time.Millisecond):
+			if tc.cancelled {
+				t.Errorf("%s: expected a cancel", tc.name)
+			}
+		}
+
+		server.Close()
+	}
+}
+
+func TestPlaceWithTTLContext(t *testing.T) {
+	cancelled := make(chan struct{}, 1)
+	c, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "POST", "GET":
+			fmt.Fprint(w, `{"ok": true, "id": 7, "open": true}`)
+		case "DELETE":
+			cancelled <- struct{}{}
+			fmt.Fprint(w, `{"ok": true, "id": 7, "open": false}`)
+		}
+	})
+	defer server.Close()
+	c.PollInterval = 5 * time.Millisecond
+
+	// done as soon as the order's placed
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	if _, err := c.PlaceWithTTL(ctx, TestAccount, TestExchange, TestStock, 100, 10, Buy, Limit, 50*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Error("expected the order cancelled after the TTL, even with ctx done")
+	}
+
+	// Shutdown stops the watching
+	if _, err := c.PlaceWithTTL(context.Background(), TestAccount, TestExchange, TestStock, 100, 10, Buy, Limit, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	shutdown, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := c.Shutdown(shutdown); err != nil {
+		t.Errorf("expected Shutdown to stop the TTL watcher, got %v", err)
+	}
+}
+
+func TestPlaceWithTTLSlowStatus(t *testing.T) {
+	hang := make(chan struct{})
+	cancelled := make(chan struct{}, 1)
+	c, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "POST":
+			fmt.Fprint(w, `{"ok": true, "id": 7, "open": true}`)
+		case "GET":
+			<-hang
+		case "DELETE":
+			cancelled <- struct{}{}
+			fmt.Fprint(w, `{"ok": true, "id": 7, "open": false}`)
+		}
+	})
+	defer server.Close()
+	defer close(hang)
+	c.PollInterval = 5 * time.Millisecond
+
+	if _, err := c.PlaceWithTTL(context.Background(), TestAccount, TestExchange, TestStock, 100, 10, Buy, Limit, 50*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Error("expected the cancel on time, even with a status check still out")
+	}
+}
+
+func TestPlaceWithTTLClock(t *testing.T) {
+	clk := &fakeClock{now: time.Date(2016, 1, 1, 12, 0, 0, 0, time.UTC)}
+	cancelled := make(chan struct{}, 1)
+	c, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "POST", "GET":
+			fmt.Fprint(w, `{"ok": true, "id": 7, "open": true}`)
+		case "DELETE":
+			cancelled <- struct{}{}
+			fmt.Fprint(w, `{"ok": true, "id": 7, "open": false}`)
+		}
+	})
+	defer server.Close()
+	c.timeSource = clk
+
+	// the fake clock runs out the hour straight away
+	if _, err := c.PlaceWithTTL(context.Background(), TestAccount, TestExchange, TestStock, 100, 10, Buy, Limit, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("expected the cancel once the client's clock passed the TTL")
+	}
+
+	clk.mu.Lock()
+	defer clk.mu.Unlock()
+	if len(clk.waits) == 0 || clk.waits[0] != time.Hour {
+		t.Errorf("expected the TTL to be waited on the client's clock, got %v", clk.waits)
+	}
+}
+
+func TestPlacePegged(t *testing.T) {
+	book := `{"ok": true, "bids": [{"price": 99, "qty": 10}, {"price": 100, "qty": 5}], "asks": [{"price": 105, "qty": 10}]}`
+	var placed map[string]interface{}
+
+	c, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "POST" {
+			placed = map[string]interface{}{}
+			json.NewDecoder(r.Body).Decode(&placed)
+			fmt.Fprint(w, `{"ok": true, "id": 1}`)
+			return
+		}
+		fmt.Fprint(w, book)
+	})
+	defer server.Close()
+
+	for _, tc := range []struct {
+		side   Direction
+		offset int
+		price  float64
+	}{
+		{Buy, 1, 101},
+		{Buy, -2, 98},
+		{Sell, -1, 104},
+	} {
+		if _, err := c.PlacePegged(context.Background(), TestAccount, TestExchange, TestStock, tc.side, 10, tc.offset); err != nil {
+			t.Fatal(err)
+		}
+		if placed["price"] != tc.price || placed["direction"] != string(tc.side) || placed["orderType"] != "limit" {
+			t.Errorf("%s %+d: unexpected order %v", tc.side, tc.offset, placed)
+		}
+	}
+
+	book = `{"ok": true, "bids": [], "asks": [{"price": 105, "qty": 10}]}`
+	if _, err := c.PlacePegged(context.Background(), TestAccount, TestExchange, TestStock, Buy, 10, 0); err != ErrNothingToPeg {
+		t.Errorf("expected ErrNothingToPeg, got %v", err)
+	}
+}
+
+func TestReduceOrder(t *testing.T) {
+	// what the status says is left, then what the cancel says was
+	remaining, left := 60, 60
+	var placed orderRequest
+	var cancelled, reject bool
+
+	c, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "GET":
+			fmt.Fprintf(w, `{"ok": true, "id": 7, "originalQty": 100, "qty": %d, "totalFilled": %d, "open": true}`, remaining, 100-remaining)
+		case "DELETE":
+			cancelled = true
+			fmt.Fprintf(w, `{"ok": true, "id": 7, "account": %q, "direction": "sell", "orderType": "limit", "price": 5100, "originalQty": 100, "qty": 0, "totalFilled": %d, "open": false}`,
+				TestAccount, 100-left)
+		case "POST":
+			if reject {
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprint(w, `{"ok": false, "error": "Venue is closed"}`)
+				return
+			}
+			placed = orderRequest{}
+			json.NewDecoder(r.Body).Decode(&placed)
+			fmt.Fprintf(w, `{"ok": true, "id": 8, "qty": %d, "open": true}`, placed.Qty)
+		}
+	})
+	defer server.Close()
+
+	replacement, filled, err := c.ReduceOrder(context.Background(), TestExchange, TestStock, 7, 25)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !cancelled {
+		t.Error("expected the order to be cancelled")
+	}
+	expected := orderRequest{TestAccount, TestExchange, TestStock, 5100, 25, "sell", "limit"}
+	if placed != expected {
+		t.Errorf("expected replacement %+v, got %+v", expected, placed)
+	}
+	if replacement.ID != 8 || filled != 40 {
+		t.Errorf("expected order 8 and 40 filled, got order %d and %d filled", replacement.ID, filled)
+	}
+
+	// filled down to less than asked for between the status and the cancel
+	left = 10
+	if _, _, err = c.ReduceOrder(context.Background(), TestExchange, TestStock, 7, 25); err != nil {
+		t.Fatal(err)
+	}
+	if placed.Qty != 10 {
+		t.Errorf("expected the replacement to be for the 10 left, got %d", placed.Qty)
+	}
+
+	left = 0
+	placed = orderRequest{}
+	replacement, filled, err = c.ReduceOrder(context.Background(), TestExchange, TestStock, 7, 25)
+	if err != nil || replacement != nil || filled != 100 || placed.Qty != 0 {
+		t.Errorf("expected nothing placed for a filled order, got %+v, %d, %v", replacement, filled, err)
+	}
+
+	// cancelled, but the replacement didn't go through
+	left, reject = 60, true
+	replacement, filled, err = c.ReduceOrder(context.Background(), TestExchange, TestStock, 7, 25)
+	var apiErr *APIError
+	if !errors.Is(err, ErrNotReplaced) || !errors.As(err, &apiErr) || replacement != nil || filled != 40 {
+		t.Errorf("expected ErrNotReplaced wrapping the rejection, got %+v, %d, %v", replacement, filled, err)
+	}
+	reject = false
+
+	// no more left than asked for isn't a reduction, so the order keeps its
+	// place in the queue
+	for _, newQty := range []int64{60, 80} {
+		cancelled = false
+		if _, _, err = c.ReduceOrder(context.Background(), TestExchange, TestStock, 7, newQty); !errors.Is(err, ErrNotReduced) {
+			t.Errorf("%d: expected ErrNotReduced, got %v", newQty, err)
+		}
+		if cancelled {
+			t.Errorf("%d: expected the order not to be cancelled", newQty)
+		}
+	}
+}
+
+func TestPlaceLadder(t *testing.T) {
+	var mu sync.Mutex
+	placed := map[int64]string{}
+	cancelled := map[string]bool{}
+	reject := int64(0)
+
+	c, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if r.Method == "DELETE" {
+			cancelled[path.Base(r.URL.Path)] = true
+			fmt.Fprint(w, `{"ok": true, "open": false}`)
+			return
+		}
+
+		order := orderRequest{}
+		json.NewDecoder(r.Body).Decode(&order)
+		if order.Price == reject {
+			fmt.Fprint(w, `{"ok": false, "error": "no"}`)
+			return
+		}
+		placed[order.Price] = order.Direction
+		fmt.Fprintf(w, `{"ok": true, "id": %d, "price": %d, "direction": %q, "qty": %d, "open": true}`, order.Price, order.Price, order.Direction, order.Qty)
+	})
+	defer server.Close()
+
+	results, err := c.PlaceLadder(context.Background(), TestAccount, TestExchange, TestStock, 1000, 5, 10, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []struct {
+		price     int
+		direction Direction
+	}{{995, Buy}, {990, Buy}, {985, Buy}, {1005, Sell}, {1010, Sell}, {1015, Sell}}
+
+	if len(results) != len(expected) || len(placed) != len(expected) {
+		t.Fatalf("expected %d orders, got %d (%d placed)", len(expected), len(results), len(placed))
+	}
+	for k, e := range expected {
+		if results[k].Price != e.price || results[k].Direction != e.direction || results[k].Qty != 10 {
+			t.Errorf("level %d: expected %s 10 at %d, got %+v", k, e.direction, e.price, results[k])
+		}
+	}
+
+	// one level fails: without rollback the rest stand, with it they don't
+	placed = map[int64]string{}
+	reject = 1010
+	results, err = c.PlaceLadder(context.Background(), TestAccount, TestExchange, TestStock, 1000, 5, 10, 3)
+	if err == nil || len(results) != 5 || len(cancelled) != 0 {
+		t.Errorf("expected 5 orders and an error, got %d, %v (%d cancelled)", len(results), err, len(cancelled))
+	}
+
+	results, err = c.PlaceLadderAllOrNone(context.Background(), TestAccount, TestExchange, TestStock, 1000, 5, 10, 3)
+	if err == nil || results != nil {
+		t.Errorf("expected nothing and an error, got %v, %v", results, err)
+	}
+	if len(cancelled) != 5 || cancelled["1010"] {
+		t.Errorf("expected the 5 placed orders to be cancelled, got %v", cancelled)
+	}
+
+	if _, err = c.PlaceLadder(context.Background(), TestAccount, TestExchange, TestStock, 10, 5, 10, 2); err == nil {
+		t.Error("expected a ladder below zero to be turned down")
+	}
+}
+
+func TestCancelAllForStock(t *testing.T) {
+	m := NewMockVenue()
+	defer m.Close()
+	c := m.NewClient()
+
+	place := func(stock string, price int64, direction Direction) int {
+		t.Helper()
+		order, err := c.PlaceStockOrder(TestAccount, TestExchange, stock, price, 10, string(direction), string(Limit))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return order.ID
+	}
+
+	bid, ask := place(TestStock, 99, Buy), place(TestStock, 101, Sell)
+	other := place("BAZ", 50, Buy)
+	// filled against the bid, so already closed
+	if _, err := c.PlaceStockOrder("SELLER", TestExchange, TestStock, 99, 10, string(Sell), string(Limit)); err != nil {
+		t.Fatal(err)
+	}
+
+	cancelled, err := c.CancelAllForStock(context.Background(), TestExchange, TestStock, TestAccount)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cancelled) != 1 || cancelled[0].ID != ask || cancelled[0].Open {
+		t.Errorf("expected just order %d cancelled (order %d had filled), got %+v", ask, bid, cancelled)
+	}
+
+	status, err := c.GetOrderStatus(TestExchange, "BAZ", int64(other))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !status.Open {
+		t.Error("expected the BAZ order to be left alone")
+	}
+}
@@ -0,0 +1,121 @@
+package starfighter
+
+import (
+	"math"
+	"sort"
+)
+
+// lot is an open position left over from a fill. Qty is negative for shorts.
+type lot struct {
+	price int
+	qty   int
+}
+
+// PnL works out the realized and unrealized profit (in cents) of a set of
+// fills, given in the order they happened. Buys and sells are matched FIFO;
+// whatever is left open, long or short, is marked at currentMid.
+// Every fill needs its Direction set.
+func PnL(fills []Fill, currentMid float64) (realizedCents, unrealizedCents int) {
+	lots := []lot{}
+
+	for _, fill := range fills {
+		qty := fill.Qty
+		if fill.Direction == Sell {
+			qty = -qty
+		}
+
+		// close out lots on the other side, oldest first
+		for qty != 0 && len(lots) > 0 && (lots[0].qty > 0) != (qty > 0) {
+			matched := min(abs(qty), abs(lots[0].qty))
+
+			if lots[0].qty > 0 {
+				realizedCents += matched * (fill.Price - lots[0].price)
+				lots[0].qty -= matched
+				qty += matched
+			} else {
+				realizedCents += matched * (lots[0].price - fill.Price)
+				lots[0].qty += matched
+				qty -= matched
+			}
+
+			if lots[0].qty == 0 {
+				lots = lots[1:]
+			}
+		}
+
+		if qty != 0 {
+			lots = append(lots, lot{price: fill.Price, qty: qty})
+		}
+	}
+
+	unrealized := 0.0
+	for _, l := range lots {
+		unrealized += float64(l.qty) * (currentMid - float64(l.price))
+	}
+
+	return realizedCents, int(math.Round(unrealized))
+}
+
+// SpreadCapture is what a market maker made (in cents) on the shares it both
+// bought and sold: each sell matched FIFO against the buys, or the other
+// way round when short, in the order the fills happened. Whatever's left
+// unmatched is inventory, not spread, so it doesn't count, which keeps the
+// market moving under an open position out of it. The fills' Directions
+// don't need to be set.
+func SpreadCapture(buys, sells []Fill) int {
+	fills := make([]Fill, 0, len(buys)+len(sells))
+	for _, fill := range buys {
+		fill.Direction = Buy
+		fills = append(fills, fill)
+	}
+	for _, fill := range sells {
+		fill.Direction = Sell
+		fills = append(fills, fill)
+	}
+
+	sort.SliceStable(fills, func(i, j int) bool {
+		return fills[i].Timestamp.Before(fills[j].Timestamp)
+	})
+
+	captured, _ := PnL(fills, 0)
+	return captured
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// NetPosition is the number of shares the orders leave you holding,
+// negative if you're short.
+func NetPosition(orders []OrderResultAlt) int {
+	position := 0
+	for _, order := range orders {
+		if order.Direction == Sell {
+			position -= order.TotalFilled
+		} else {
+			position += order.TotalFilled
+		}
+	}
+	return position
+}
+
+// MaxDrawdown is the biggest fall, in cents, from a high in the equity curve
+// to a low after it, and where the two were. A curve that never falls has
+// no drawdown, and both indices are 0.
+func MaxDrawdown(equityCents []int) (drawdownCents int, peakIndex, troughIndex int) {
+	peak := 0
+	for k, equity := range equityCents {
+		if equity > equityCents[peak] {
+			peak = k
+		}
+
+		if fall := equityCents[peak] - equity; fall > drawdownCents {
+			drawdownCents, peakIndex, troughIndex = fall, peak, k
+		}
+	}
+
+	return drawdownCents, peakIndex, troughIndex
+}
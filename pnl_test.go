@@ -0,0 +1,92 @@
+package starfighter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPnLFIFO(t *testing.T) {
+	fills := []Fill{
+		{Price: 100, Qty: 10, Direction: Buy},
+		{Price: 110, Qty: 10, Direction: Buy},
+		{Price: 120, Qty: 15, Direction: Sell}, // 10 @ 100 and 5 @ 110 close out
+		{Price: 105, Qty: 5, Direction: Buy},
+	}
+
+	// realized: 10*(120-100) + 5*(120-110) = 250
+	// open: 5 @ 110, 5 @ 105, marked at 115 = 25 + 50 = 75
+	realized, unrealized := PnL(fills, 115)
+	if realized != 250 || unrealized != 75 {
+		t.Errorf("expected (250, 75), got (%d, %d)", realized, unrealized)
+	}
+}
+
+func TestPnLShort(t *testing.T) {
+	fills := []Fill{
+		{Price: 200, Qty: 10, Direction: Sell},
+		{Price: 190, Qty: 4, Direction: Buy},
+		{Price: 180, Qty: 10, Direction: Buy}, // covers 6, goes long 4
+	}
+
+	// realized: 4*(200-190) + 6*(200-180) = 160
+	// open: long 4 @ 180, marked at 185 = 20
+	realized, unrealized := PnL(fills, 185)
+	if realized != 160 || unrealized != 20 {
+		t.Errorf("expected (160, 20), got (%d, %d)", realized, unrealized)
+	}
+
+	// still short
+	realized, unrealized = PnL(fills[:2], 195.5)
+	if realized != 40 || unrealized != 27 {
+		t.Errorf("expected (40, 27), got (%d, %d)", realized, unrealized)
+	}
+}
+
+func TestMaxDrawdown(t *testing.T) {
+	for _, c := range []struct {
+		equity              []int
+		drawdown, peak, low int
+	}{
+		// dips, recovers to a new high, then dips harder
+		{[]int{0, 100, 50, 120, 150, 40, 90, 200}, 110, 4, 5},
+		// dips and recovers, but nothing beats the first dip
+		{[]int{1000, 700, 1100, 1000, 1200}, 300, 0, 1},
+		{[]int{0, 10, 20, 30}, 0, 0, 0},
+		{[]int{50, 40, 30}, 20, 0, 2},
+		{nil, 0, 0, 0},
+	} {
+		drawdown, peak, low := MaxDrawdown(c.equity)
+		if drawdown != c.drawdown || peak != c.peak || low != c.low {
+			t.Errorf("%v: expected (%d, %d, %d), got (%d, %d, %d)", c.equity, c.drawdown, c.peak, c.low, drawdown, peak, low)
+		}
+	}
+}
+
+func TestSpreadCapture(t *testing.T) {
+	start := time.Date(2016, 1, 1, 12, 0, 0, 0, time.UTC)
+	at := func(seconds int) time.Time {
+		return start.Add(time.Duration(seconds) * time.Second)
+	}
+
+	buys := []Fill{
+		{Price: 100, Qty: 10, Timestamp: at(0)},
+		{Price: 99, Qty: 10, Timestamp: at(2)},
+		{Price: 98, Qty: 5, Timestamp: at(5)},
+	}
+	sells := []Fill{
+		{Price: 102, Qty: 15, Timestamp: at(1)},
+		{Price: 101, Qty: 10, Timestamp: at(3)},
+		{Price: 103, Qty: 5, Timestamp: at(4)},
+	}
+
+	// 10 bought at 100 sold at 102, then short 5 at 102; the 10 at 99 cover
+	// that (+15) and go long 5, sold at 101 (+10); short 5 at 101 and 5 at
+	// 103, and the last 5 at 98 cover the 101s (+15), leaving 5 short at 103
+	if captured := SpreadCapture(buys, sells); captured != 20+15+10+15 {
+		t.Errorf("expected 60, got %d", captured)
+	}
+
+	if captured := SpreadCapture(buys, nil); captured != 0 {
+		t.Errorf("expected nothing captured with no sells, got %d", captured)
+	}
+}
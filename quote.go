@@ -0,0 +1,53 @@
+package starfighter
+
+import (
+	"math"
+	"time"
+)
+
+// AgeUnknown is the age of something that never happened, like the last
+// trade of a stock that hasn't traded. It's the longest Duration there is,
+// so it counts as older than any threshold you compare it to.
+const AgeUnknown = time.Duration(math.MaxInt64)
+
+// SpreadBps is the spread as basis points of the midpoint, so spreads can be
+// compared between stocks trading at different prices. It's false if either
+// side of the quote is missing.
+func (q *StockQuote) SpreadBps() (float64, bool) {
+	if q.Bid <= 0 || q.Ask <= 0 {
+		return 0, false
+	}
+
+	mid := float64(q.Bid+q.Ask) / 2
+	return float64(q.Ask-q.Bid) / mid * 10000, true
+}
+
+// RoundTripCost is what it costs, in cents, to buy qty at the ask and sell
+// them straight back at the bid: the spread you have to make up before a
+// scalp makes anything. It doesn't look at the sizes, so it's only right if
+// there's qty on both sides. It's 0 if either side of the quote is missing.
+func (q *StockQuote) RoundTripCost(qty int) int {
+	if q.Bid <= 0 || q.Ask <= 0 {
+		return 0
+	}
+	return (q.Ask - q.Bid) * qty
+}
+
+// LastTradeAge is how long before now the stock last traded, e.g. to spot
+// one that's gone quiet. It's AgeUnknown if the quote has no last trade.
+func (q *StockQuote) LastTradeAge(now time.Time) time.Duration {
+	return age(q.LastTrade, now)
+}
+
+// QuoteAge is how long before now the quote was made, or AgeUnknown if it
+// doesn't say.
+func (q *StockQuote) QuoteAge(now time.Time) time.Duration {
+	return age(q.QuoteAt, now)
+}
+
+func age(at, now time.Time) time.Duration {
+	if at.IsZero() {
+		return AgeUnknown
+	}
+	return now.Sub(at)
+}
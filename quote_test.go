@@ -0,0 +1,67 @@
+package starfighter
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestSpreadBps(t *testing.T) {
+	for _, c := range []struct {
+		bid, ask int
+		bps      float64
+		ok       bool
+	}{
+		{9950, 10050, 100, true},
+		{100, 101, 99.50248756, true},
+		{5000, 5000, 0, true},
+		{5010, 5000, -19.98001998, true},
+		{0, 5000, 0, false},
+		{5000, 0, 0, false},
+	} {
+		quote := &StockQuote{Bid: c.bid, Ask: c.ask}
+		bps, ok := quote.SpreadBps()
+		if ok != c.ok || math.Abs(bps-c.bps) > 1e-6 {
+			t.Errorf("%d/%d: expected (%v, %v), got (%v, %v)", c.bid, c.ask, c.bps, c.ok, bps, ok)
+		}
+	}
+}
+
+func TestRoundTripCost(t *testing.T) {
+	for _, c := range []struct {
+		bid, ask, qty int
+		cost          int
+	}{
+		{100, 102, 1, 2},
+		{100, 102, 50, 100},
+		{9950, 10050, 10, 1000},
+		{5000, 5000, 100, 0},
+		{0, 5000, 100, 0},
+		{5000, 0, 100, 0},
+	} {
+		quote := &StockQuote{Bid: c.bid, Ask: c.ask}
+		if cost := quote.RoundTripCost(c.qty); cost != c.cost {
+			t.Errorf("%d/%d x %d: expected %d, got %d", c.bid, c.ask, c.qty, c.cost, cost)
+		}
+	}
+}
+
+func TestQuoteAges(t *testing.T) {
+	now := time.Date(2016, 1, 1, 12, 0, 0, 0, time.UTC)
+	quote := &StockQuote{LastTrade: now.Add(-90 * time.Second), QuoteAt: now.Add(-time.Second)}
+
+	if age := quote.LastTradeAge(now); age != 90*time.Second {
+		t.Errorf("expected the last trade 90s ago, got %v", age)
+	}
+	if age := quote.QuoteAge(now); age != time.Second {
+		t.Errorf("expected the quote 1s ago, got %v", age)
+	}
+
+	quote = &StockQuote{}
+	if age := quote.LastTradeAge(now); age != AgeUnknown {
+		t.Errorf("expected no last trade to be AgeUnknown, got %v", age)
+	}
+	if age := quote.QuoteAge(now); age != AgeUnknown {
+		t.Errorf("expected no quote time to be AgeUnknown, got %v", age)
+	}
+}
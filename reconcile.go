@@ -0,0 +1,78 @@
+package starfighter
+
+import (
+	"context"
+	"sort"
+)
+
+// ReconcileReport is how a bot's idea of its orders differs from the API's.
+type ReconcileReport struct {
+	// Orders the API knows about that the bot doesn't
+	Unknown []OrderResultAlt
+	// Orders the bot knows about that the API doesn't
+	Missing []int64
+	// Orders where the two disagree on how much has filled
+	FillMismatches []FillMismatch
+}
+
+// FillMismatch is an order the bot and the API disagree about.
+type FillMismatch struct {
+	ID           int64
+	LocalFilled  int
+	RemoteFilled int
+	// The API's version of the order
+	Order OrderResultAlt
+}
+
+// Clean says whether there's nothing to reconcile.
+func (r *ReconcileReport) Clean() bool {
+	return len(r.Unknown) == 0 && len(r.Missing) == 0 && len(r.FillMismatches) == 0
+}
+
+// Reconcile checks the orders the bot is tracking (by ID) against what the
+// API says the account has on the venue, for catching drift after a crash
+// or a missed update. Everything in the report is in order of ID. An order
+// the API lists twice is only reported once, and nil orders in local are
+// skipped, as if the bot weren't tracking them.
+func (c *Client) Reconcile(ctx context.Context, venue, account string, local map[int64]*OrderResultAlt) (ReconcileReport, error) {
+	report := ReconcileReport{}
+
+	list, err := c.listVenueOrderStatus(ctx, venue, account)
+	if err != nil {
+		return report, err
+	}
+	list.Dedup()
+
+	remote := make(map[int64]bool, len(list.Orders))
+	for _, order := range list.Orders {
+		id := int64(order.ID)
+		remote[id] = true
+
+		tracked := local[id]
+		if tracked == nil {
+			report.Unknown = append(report.Unknown, order)
+			continue
+		}
+
+		if tracked.TotalFilled != order.TotalFilled {
+			report.FillMismatches = append(report.FillMismatches, FillMismatch{
+				ID:           id,
+				LocalFilled:  tracked.TotalFilled,
+				RemoteFilled: order.TotalFilled,
+				Order:        order,
+			})
+		}
+	}
+
+	for id, tracked := range local {
+		if tracked != nil && !remote[id] {
+			report.Missing = append(report.Missing, id)
+		}
+	}
+
+	sort.Slice(report.Unknown, func(i, j int) bool { return report.Unknown[i].ID < report.Unknown[j].ID })
+	sort.Slice(report.Missing, func(i, j int) bool { return report.Missing[i] < report.Missing[j] })
+	sort.Slice(report.FillMismatches, func(i, j int) bool { return report.FillMismatches[i].ID < report.FillMismatches[j].ID })
+
+	return report, nil
+}
@@ -0,0 +1,81 @@
+package starfighter
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestReconcile(t *testing.T) {
+	c, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"ok": true, "orders": [
+			{"id": 1, "totalFilled": 10},
+			{"id": 2, "totalFilled": 25},
+			{"id": 4, "totalFilled": 0},
+			{"id": 3, "totalFilled": 5}
+		]}`)
+	})
+	defer server.Close()
+
+	local := map[int64]*OrderResultAlt{
+		1: {ID: 1, TotalFilled: 10},
+		2: {ID: 2, TotalFilled: 20},
+		5: {ID: 5},
+		6: {ID: 6},
+	}
+
+	report, err := c.Reconcile(context.Background(), TestExchange, TestAccount, local)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(report.Unknown) != 2 || report.Unknown[0].ID != 3 || report.Unknown[1].ID != 4 {
+		t.Errorf("expected orders 3 and 4 to be unknown, got %+v", report.Unknown)
+	}
+	if fmt.Sprint(report.Missing) != "[5 6]" {
+		t.Errorf("expected orders 5 and 6 to be missing, got %v", report.Missing)
+	}
+	if len(report.FillMismatches) != 1 {
+		t.Fatalf("expected one fill mismatch, got %+v", report.FillMismatches)
+	}
+	if m := report.FillMismatches[0]; m.ID != 2 || m.LocalFilled != 20 || m.RemoteFilled != 25 {
+		t.Errorf("unexpected fill mismatch: %+v", m)
+	}
+	if report.Clean() {
+		t.Error("expected the report not to be clean")
+	}
+}
+
+func TestReconcileDuplicatesAndNil(t *testing.T) {
+	c, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"ok": true, "orders": [
+			{"id": 1, "totalFilled": 10},
+			{"id": 2, "totalFilled": 5},
+			{"id": 1, "totalFilled": 20},
+			{"id": 2, "totalFilled": 5}
+		]}`)
+	})
+	defer server.Close()
+
+	local := map[int64]*OrderResultAlt{
+		1: {ID: 1, TotalFilled: 10},
+		2: nil,
+		3: nil,
+	}
+
+	report, err := c.Reconcile(context.Background(), TestExchange, TestAccount, local)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(report.FillMismatches) != 1 || report.FillMismatches[0].RemoteFilled != 20 {
+		t.Errorf("expected order 1 to be reported once, at its most filled, got %+v", report.FillMismatches)
+	}
+	if len(report.Unknown) != 1 || report.Unknown[0].ID != 2 {
+		t.Errorf("expected the nil order 2 to be unknown, and only once, got %+v", report.Unknown)
+	}
+	if len(report.Missing) != 0 {
+		t.Errorf("expected the nil order 3 to be skipped, got %v", report.Missing)
+	}
+}
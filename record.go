@@ -0,0 +1,97 @@
+package starfighter
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// recordFlushInterval is how often RecordQuotes flushes what it's written.
+const recordFlushInterval = 250 * time.Millisecond
+
+// RecordQuotes writes every quote from the venue's tickertape to w as a line
+// of JSON, until ctx is cancelled, so a session can be replayed later. The
+// output is buffered and flushed every so often, and when it stops. As with
+// ExportExecutions, cancelling ctx is the normal way to stop, so it's not an
+// error; the feed failing or w failing is.
+func (c *Client) RecordQuotes(ctx context.Context, account, venue string, w io.Writer) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	quotes, errs, err := c.SubscribeQuotes(ctx, account, venue)
+	if err != nil {
+		return err
+	}
+
+	out := bufio.NewWriter(w)
+	encoder := json.NewEncoder(out)
+
+	ticker := time.NewTicker(recordFlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case quote, ok := <-quotes:
+			if !ok {
+				if err := out.Flush(); err != nil {
+					return err
+				}
+				return <-errs
+			}
+			if err := encoder.Encode(quote); err != nil {
+				return err
+			}
+		case <-ticker.C:
+			if err := out.Flush(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// ReplayQuotes reads quotes written by RecordQuotes from r and sends them on
+// the quote channel, the way SubscribeQuotes would, so a backtest can run
+// on a recording. The gaps between quotes follow their QuoteAt times,
+// divided by speed: 10 replays ten times as fast, and zero or less doesn't
+// wait at all. The channels behave as they do for SubscribeQuotes, closing
+// at the end of the recording; a line that can't be read is an error.
+func ReplayQuotes(ctx context.Context, r io.Reader, speed float64) (<-chan StockQuote, <-chan error) {
+	quotes := make(chan StockQuote)
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(errs)
+		defer close(quotes)
+
+		decoder := json.NewDecoder(r)
+		var last time.Time
+		for {
+			quote := StockQuote{}
+			if err := decoder.Decode(&quote); err != nil {
+				if err != io.EOF {
+					errs <- err
+				}
+				return
+			}
+
+			if speed > 0 && !last.IsZero() && quote.QuoteAt.After(last) {
+				if !sleep(ctx, time.Duration(float64(quote.QuoteAt.Sub(last))/speed)) {
+					return
+				}
+			}
+			if !quote.QuoteAt.IsZero() {
+				last = quote.QuoteAt
+			}
+
+			select {
+			case quotes <- quote:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return quotes, errs
+}
@@ -0,0 +1,86 @@
+package starfighter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// cancelAfterLines cancels once n lines have been written to it.
+type cancelAfterLines struct {
+	bytes.Buffer
+	n      int
+	cancel context.CancelFunc
+}
+
+func (w *cancelAfterLines) Write(p []byte) (int, error) {
+	n, err := w.Buffer.Write(p)
+	if bytes.Count(w.Bytes(), []byte("\n")) >= w.n {
+		w.cancel()
+	}
+	return n, err
+}
+
+func TestRecordQuotes(t *testing.T) {
+	c, server := newFeedServer(t, nil, []string{quoteFrame(TestStock, 100), quoteFrame("BAZ", 200)}, nil, nil)
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	out := &cancelAfterLines{n: 2, cancel: cancel}
+	if err := c.RecordQuotes(ctx, TestAccount, TestExchange, out); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %q", out.String())
+	}
+	for k, expected := range []StockQuote{{Symbol: TestStock, Venue: TestExchange, Last: 100}, {Symbol: "BAZ", Venue: TestExchange, Last: 200}} {
+		quote := StockQuote{}
+		if err := json.Unmarshal([]byte(lines[k]), &quote); err != nil {
+			t.Fatal(err)
+		}
+		if quote != expected {
+			t.Errorf("line %d: expected %+v, got %+v", k, expected, quote)
+		}
+	}
+}
+
+func TestReplayQuotes(t *testing.T) {
+	recording := `{"symbol": "FOOBAR", "last": 100, "quoteTime": "2016-01-01T12:00:00Z"}
+{"symbol": "FOOBAR", "last": 101, "quoteTime": "2016-01-01T12:00:01Z"}
+{"symbol": "FOOBAR", "last": 102, "quoteTime": "2016-01-01T12:00:03Z"}
+`
+
+	start := time.Now()
+	quotes, errs := ReplayQuotes(context.Background(), strings.NewReader(recording), 100)
+
+	lasts := []int{}
+	for quote := range quotes {
+		lasts = append(lasts, quote.Last)
+	}
+	if err := <-errs; err != nil {
+		t.Fatal(err)
+	}
+
+	if fmt.Sprint(lasts) != "[100 101 102]" {
+		t.Errorf("expected every quote, got %v", lasts)
+	}
+	// 3 seconds at 100x
+	if elapsed := time.Since(start); elapsed < 30*time.Millisecond || elapsed > time.Second {
+		t.Errorf("expected about 30ms, took %v", elapsed)
+	}
+
+	quotes, errs = ReplayQuotes(context.Background(), strings.NewReader(recording+"garbage\n"), 0)
+	for range quotes {
+	}
+	if err := <-errs; err == nil {
+		t.Error("expected the garbage to be an error")
+	}
+}
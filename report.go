@@ -0,0 +1,97 @@
+package starfighter
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"time"
+)
+
+// StatusReport is what Report says about an account on a venue. PnL is in
+// cents.
+type StatusReport struct {
+	Venue         string           `json:"venue"`
+	Account       string           `json:"account"`
+	At            time.Time        `json:"at"`
+	OpenOrders    []OrderResultAlt `json:"openOrders"`
+	Stocks        []StockReport    `json:"stocks"`
+	RealizedPnL   int              `json:"realizedPnl"`
+	UnrealizedPnL int              `json:"unrealizedPnl"`
+}
+
+// StockReport is a StatusReport's view of one stock the account has traded
+// or has orders in. Mid is what the position was marked at, or 0 if it's
+// flat and didn't need marking.
+type StockReport struct {
+	Symbol        string  `json:"symbol"`
+	Position      int     `json:"position"`
+	Mid           float64 `json:"mid"`
+	RealizedPnL   int     `json:"realizedPnl"`
+	UnrealizedPnL int     `json:"unrealizedPnl"`
+}
+
+// Report sums up the account on the venue as JSON (a StatusReport), for a
+// bot to log or serve now and then: its open orders, and its position and
+// PnL in each stock, marked at the midpoint (or the last trade, if the
+// quote is missing a side).
+func (c *Client) Report(ctx context.Context, venue, account string) ([]byte, error) {
+	orders, err := c.listVenueOrderStatus(ctx, venue, account)
+	if err != nil {
+		return nil, err
+	}
+
+	report := StatusReport{
+		Venue:      venue,
+		Account:    account,
+		At:         c.clock().Now(),
+		OpenOrders: []OrderResultAlt{},
+		Stocks:     []StockReport{},
+	}
+
+	bySymbol := map[string][]OrderResultAlt{}
+	for _, order := range orders.Orders {
+		bySymbol[order.Symbol] = append(bySymbol[order.Symbol], order)
+		if order.Open {
+			report.OpenOrders = append(report.OpenOrders, order)
+		}
+	}
+
+	symbols := make([]string, 0, len(bySymbol))
+	for symbol := range bySymbol {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+
+	for _, symbol := range symbols {
+		stock := StockReport{Symbol: symbol, Position: NetPosition(bySymbol[symbol])}
+
+		fills := []Fill{}
+		for _, order := range bySymbol[symbol] {
+			for _, fill := range order.Fills {
+				fill.Direction = order.Direction
+				fills = append(fills, fill)
+			}
+		}
+		sort.SliceStable(fills, func(i, j int) bool {
+			return fills[i].Timestamp.Before(fills[j].Timestamp)
+		})
+
+		if stock.Position != 0 {
+			quote, err := c.quoteStock(ctx, venue, symbol)
+			if err != nil {
+				return nil, err
+			}
+			stock.Mid = float64(quote.Last)
+			if quote.Bid > 0 && quote.Ask > 0 {
+				stock.Mid = float64(quote.Bid+quote.Ask) / 2
+			}
+		}
+
+		stock.RealizedPnL, stock.UnrealizedPnL = PnL(fills, stock.Mid)
+		report.RealizedPnL += stock.RealizedPnL
+		report.UnrealizedPnL += stock.UnrealizedPnL
+		report.Stocks = append(report.Stocks, stock)
+	}
+
+	return json.Marshal(report)
+}
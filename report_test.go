@@ -0,0 +1,73 @@
+package starfighter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReport(t *testing.T) {
+	now := time.Date(2016, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	c, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == fmt.Sprintf("/venues/%s/accounts/%s/orders", TestExchange, TestAccount):
+			fmt.Fprint(w, `{"ok": true, "orders": [
+				{"id": 1, "symbol": "FOOBAR", "direction": "buy", "totalFilled": 20, "open": false, "fills": [
+					{"price": 100, "qty": 20, "ts": "2016-01-01T11:00:00Z"}
+				]},
+				{"id": 2, "symbol": "FOOBAR", "direction": "sell", "totalFilled": 5, "qty": 5, "open": true, "fills": [
+					{"price": 104, "qty": 5, "ts": "2016-01-01T11:01:00Z"}
+				]},
+				{"id": 3, "symbol": "BAZ", "direction": "buy", "qty": 10, "open": true, "fills": []}
+			]}`)
+		case strings.HasSuffix(r.URL.Path, "/stocks/FOOBAR/quote"):
+			fmt.Fprint(w, `{"ok": true, "bid": 101, "ask": 103, "last": 102}`)
+		default:
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+	})
+	defer server.Close()
+	c.timeSource = &fakeClock{now: now}
+
+	data, err := c.Report(context.Background(), TestExchange, TestAccount)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report := StatusReport{}
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatal(err)
+	}
+
+	if report.Venue != TestExchange || report.Account != TestAccount || !report.At.Equal(now) {
+		t.Errorf("unexpected header %+v", report)
+	}
+	if len(report.OpenOrders) != 2 || report.OpenOrders[0].ID != 2 || report.OpenOrders[1].ID != 3 {
+		t.Errorf("expected orders 2 and 3 open, got %+v", report.OpenOrders)
+	}
+
+	// 5 sold at 104 against 100 is 20 realized; the other 15 marked at 102
+	// is 30 unrealized. Nothing's happened in BAZ yet.
+	expected := []StockReport{
+		{Symbol: "BAZ"},
+		{Symbol: "FOOBAR", Position: 15, Mid: 102, RealizedPnL: 20, UnrealizedPnL: 30},
+	}
+	if fmt.Sprint(report.Stocks) != fmt.Sprint(expected) {
+		t.Errorf("expected %+v, got %+v", expected, report.Stocks)
+	}
+	if report.RealizedPnL != 20 || report.UnrealizedPnL != 30 {
+		t.Errorf("expected 20 realized and 30 unrealized, got %d and %d", report.RealizedPnL, report.UnrealizedPnL)
+	}
+
+	// and the field names are what monitoring will look for
+	for _, field := range []string{`"openOrders"`, `"stocks"`, `"realizedPnl"`, `"unrealizedPnl"`, `"position"`, `"mid"`} {
+		if !strings.Contains(string(data), field) {
+			t.Errorf("expected %s in %s", field, data)
+		}
+	}
+}
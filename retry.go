@@ -0,0 +1,62 @@
+package starfighter
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+const (
+	// statusBackoff is how long to wait before the first order status
+	// retry; it doubles for each one after that.
+	statusBackoff = 100 * time.Millisecond
+	// statusMaxBackoff is the longest it'll wait between retries.
+	statusMaxBackoff = 10 * time.Second
+)
+
+// callOrderStatus makes a call for an existing order (getting its status or
+// cancelling it). Both are idempotent, so timeouts, transport errors and
+// server errors are retried StatusRetries times, each attempt getting
+// StatusTimeout regardless of the HTTP client's own timeout. The delays
+// between them are jittered according to StatusJitter.
+func (c *Client) callOrderStatus(ctx context.Context, method, endpoint string) (*bytes.Buffer, error) {
+	retries := c.StatusRetries
+	if retries == 0 {
+		retries = DefaultStatusRetries
+	}
+
+	client := *c.httpClient(method)
+	client.Timeout = c.StatusTimeout
+	if client.Timeout <= 0 {
+		client.Timeout = DefaultStatusTimeout
+	}
+
+	delays := newBackoff(c.StatusJitter, statusBackoff, statusMaxBackoff)
+
+	for attempt := 0; ; attempt++ {
+		_, copy, err := c.call(ctx, &client, method, endpoint, nil)
+		if err == nil || attempt >= retries || !retryable(ctx, err) {
+			return copy, err
+		}
+
+		if !sleep(ctx, delays.next(attempt)) {
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// retryable says whether a call that failed with err is worth another go.
+func retryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil || errors.Is(err, ErrMaintenance) {
+		return false
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Code >= http.StatusInternalServerError
+	}
+
+	return true
+}
@@ -0,0 +1,63 @@
+package starfighter
+
+import (
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGetOrderStatusRetriesTimeout(t *testing.T) {
+	calls := int64(0)
+	c, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt64(&calls, 1) == 1 {
+			time.Sleep(100 * time.Millisecond)
+		}
+		fmt.Fprint(w, `{"ok": true, "id": 7, "open": true}`)
+	})
+	defer server.Close()
+	c.StatusTimeout = 20 * time.Millisecond
+
+	order, err := c.GetOrderStatus(TestExchange, TestStock, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if order.ID != 7 {
+		t.Errorf("unexpected order: %+v", order)
+	}
+	if calls != 2 {
+		t.Errorf("expected 2 calls, got %d", calls)
+	}
+}
+
+func TestCancelOrderRetryLimit(t *testing.T) {
+	calls := int64(0)
+	c, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&calls, 1)
+		w.WriteHeader(http.StatusBadGateway)
+	})
+	defer server.Close()
+	c.StatusRetries = 1
+
+	if _, err := c.CancelOrder(TestExchange, TestStock, 7); err == nil {
+		t.Fatal("expected an error")
+	}
+	if calls != 2 {
+		t.Errorf("expected 2 calls, got %d", calls)
+	}
+
+	// client errors aren't worth retrying
+	calls = 0
+	c.StatusRetries = 0
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&calls, 1)
+		w.WriteHeader(http.StatusNotFound)
+	})
+	if _, err := c.CancelOrder(TestExchange, TestStock, 7); err == nil {
+		t.Fatal("expected an error")
+	}
+	if calls != 1 {
+		t.Errorf("expected 1 call, got %d", calls)
+	}
+}
@@ -0,0 +1,88 @@
+package starfighter
+
+import (
+	"math"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// slaSamples is how many of the latest response times an SLA goes by.
+const slaSamples = 100
+
+// slaMonitor watches the response times of the requests to an endpoint.
+type slaMonitor struct {
+	p99      time.Duration
+	callback func()
+	samples  []time.Duration
+	breached bool
+}
+
+// add records a response time, and says whether the p99 just went over.
+func (m *slaMonitor) add(latency time.Duration) bool {
+	m.samples = append(m.samples, latency)
+	if len(m.samples) > slaSamples {
+		m.samples = m.samples[len(m.samples)-slaSamples:]
+	}
+
+	sorted := append([]time.Duration(nil), m.samples...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	p99 := sorted[int(math.Ceil(0.99*float64(len(sorted))))-1]
+
+	over := p99 > m.p99
+	fire := over && !m.breached
+	m.breached = over
+	return fire
+}
+
+// SetSLACallback calls cb when the 99th percentile of the last 100
+// response times from the endpoint goes over p99, so a bot can back off
+// when the API's struggling. endpoint is a path, as passed to Call, and
+// may have wildcards as for path.Match, e.g. "/venues/*/stocks/*/quote".
+// cb is only called again once the p99 has come back under. It's called
+// on whichever request pushed it over, so keep it quick. A nil cb removes
+// the monitor.
+func (c *Client) SetSLACallback(endpoint string, p99 time.Duration, cb func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if cb == nil {
+		delete(c.slas, endpoint)
+		return
+	}
+
+	if c.slas == nil {
+		c.slas = map[string]*slaMonitor{}
+	}
+	c.slas[endpoint] = &slaMonitor{p99: p99, callback: cb}
+}
+
+// recordLatency adds the request's response time to the monitors for its
+// endpoint, calling any whose SLA it breaks.
+func (c *Client) recordLatency(req *http.Request, latency time.Duration) {
+	c.mu.Lock()
+	if len(c.slas) == 0 {
+		c.mu.Unlock()
+		return
+	}
+
+	endpoint := req.URL.Path
+	if location, err := url.Parse(c.Location); err == nil {
+		endpoint = strings.TrimPrefix(endpoint, strings.TrimSuffix(location.Path, "/"))
+	}
+
+	var breached []func()
+	for pattern, monitor := range c.slas {
+		if ok, _ := path.Match(pattern, endpoint); ok && monitor.add(latency) {
+			breached = append(breached, monitor.callback)
+		}
+	}
+	c.mu.Unlock()
+
+	for _, cb := range breached {
+		cb()
+	}
+}
@@ -0,0 +1,49 @@
+package starfighter
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSLACallback(t *testing.T) {
+	var slow int32
+	c, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/quote") && atomic.LoadInt32(&slow) == 1 {
+			time.Sleep(50 * time.Millisecond)
+		}
+		fmt.Fprint(w, `{"ok": true}`)
+	})
+	defer server.Close()
+	// as if the API were somewhere under the server's root
+	c.Location = server.URL + "/ob/api"
+
+	fired := 0
+	c.SetSLACallback("/venues/*/stocks/*/quote", 20*time.Millisecond, func() { fired++ })
+
+	for k := 0; k < 10; k++ {
+		c.QuoteStock(TestExchange, TestStock)
+	}
+	if fired != 0 {
+		t.Fatalf("expected fast quotes to be fine, fired %d times", fired)
+	}
+
+	// a slow one is over the p99 straight away, but only fires once
+	atomic.StoreInt32(&slow, 1)
+	c.QuoteStock(TestExchange, TestStock)
+	c.QuoteStock(TestExchange, TestStock)
+	if fired != 1 {
+		t.Errorf("expected the slow quotes to fire once, fired %d times", fired)
+	}
+
+	// other endpoints don't count
+	for k := 0; k < 3; k++ {
+		c.GetStockOrderbook(TestExchange, TestStock)
+	}
+	if fired != 1 {
+		t.Errorf("expected the orderbook not to count, fired %d times", fired)
+	}
+}
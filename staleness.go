@@ -0,0 +1,39 @@
+package starfighter
+
+import (
+	"sync"
+	"time"
+)
+
+// StalenessGuard catches orderbook snapshots that are older than one we've
+// already seen for the same stock, which happens when a cache somewhere
+// hands back an old response. Check every book you poll with IsRegression
+// and skip the ones it flags. It's safe to use from several goroutines.
+type StalenessGuard struct {
+	mu   sync.Mutex
+	last map[string]time.Time
+}
+
+// NewStalenessGuard creates a StalenessGuard that hasn't seen anything.
+func NewStalenessGuard() *StalenessGuard {
+	return &StalenessGuard{last: map[string]time.Time{}}
+}
+
+// IsRegression says whether the book's timestamp is before the latest seen
+// for its venue and stock. If not, it's now the latest. Books with no
+// timestamp can't be judged, so they're never flagged.
+func (g *StalenessGuard) IsRegression(book *OrderBook) bool {
+	if book.Timestamp.IsZero() {
+		return false
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	key := book.Venue + "/" + book.Symbol
+	if book.Timestamp.Before(g.last[key]) {
+		return true
+	}
+	g.last[key] = book.Timestamp
+	return false
+}
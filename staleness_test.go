@@ -0,0 +1,32 @@
+package starfighter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStalenessGuard(t *testing.T) {
+	start := time.Date(2016, 1, 1, 12, 0, 0, 0, time.UTC)
+	book := func(symbol string, seconds int) *OrderBook {
+		return &OrderBook{Venue: TestExchange, Symbol: symbol, Timestamp: start.Add(time.Duration(seconds) * time.Second)}
+	}
+
+	g := NewStalenessGuard()
+	for _, c := range []struct {
+		book       *OrderBook
+		regression bool
+	}{
+		{book(TestStock, 0), false},
+		{book(TestStock, 2), false},
+		{book(TestStock, 2), false},
+		{book(TestStock, 1), true},
+		{book("BAZ", 1), false},
+		{book(TestStock, 3), false},
+		{book(TestStock, 2), true},
+		{&OrderBook{Venue: TestExchange, Symbol: TestStock}, false},
+	} {
+		if regression := g.IsRegression(c.book); regression != c.regression {
+			t.Errorf("%s at %v: expected %v, got %v", c.book.Symbol, c.book.Timestamp, c.regression, regression)
+		}
+	}
+}
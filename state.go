@@ -0,0 +1,92 @@
+package starfighter
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+)
+
+// clientState is everything DumpState saves.
+type clientState struct {
+	Orders     []OrderResultAlt          `json:"orders"`
+	Positions  map[string]int64          `json:"positions"`
+	StockInfo  map[string]StockInfo      `json:"stockInfo"`
+	Orderbooks map[string]orderbookState `json:"orderbooks"`
+}
+
+// orderbookState is a cachedOrderbook that can be marshalled.
+type orderbookState struct {
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"lastModified,omitempty"`
+	Book         OrderBook `json:"book"`
+}
+
+// DumpState writes what the client is keeping track of (open orders,
+// positions, stock info and cached orderbooks) to w as JSON, so a bot that
+// falls over can pick up where it left off with LoadState.
+func (c *Client) DumpState(w io.Writer) error {
+	c.mu.Lock()
+
+	state := clientState{
+		Orders:     make([]OrderResultAlt, 0, len(c.orders)),
+		Positions:  map[string]int64{},
+		StockInfo:  map[string]StockInfo{},
+		Orderbooks: map[string]orderbookState{},
+	}
+	for _, order := range c.orders {
+		state.Orders = append(state.Orders, order)
+	}
+	for symbol, qty := range c.positions {
+		state.Positions[symbol] = qty
+	}
+	for key, info := range c.stockInfo {
+		state.StockInfo[key] = info
+	}
+	for key, cached := range c.orderbooks {
+		state.Orderbooks[key] = orderbookState{
+			ETag:         cached.etag,
+			LastModified: cached.lastModified,
+			Book:         *cached.Book(),
+		}
+	}
+
+	c.mu.Unlock()
+
+	// the map makes the order random, which is no good for diffing dumps
+	sort.Slice(state.Orders, func(i, j int) bool { return state.Orders[i].ID < state.Orders[j].ID })
+
+	return json.NewEncoder(w).Encode(&state)
+}
+
+// LoadState replaces what the client is keeping track of with a dump
+// written by DumpState.
+func (c *Client) LoadState(r io.Reader) error {
+	state := clientState{}
+	if err := json.NewDecoder(r).Decode(&state); err != nil {
+		return err
+	}
+
+	orders := make(map[int]OrderResultAlt, len(state.Orders))
+	for _, order := range state.Orders {
+		orders[order.ID] = order
+	}
+
+	orderbooks := make(map[string]*cachedOrderbook, len(state.Orderbooks))
+	for key, saved := range state.Orderbooks {
+		orderbooks[key] = &cachedOrderbook{
+			etag:         saved.ETag,
+			lastModified: saved.LastModified,
+			book:         saved.Book,
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.orders = orders
+	c.positions = state.Positions
+	c.stockInfo = state.StockInfo
+	c.orderbooks = orderbooks
+
+	return nil
+}
@@ -0,0 +1,68 @@
+package starfighter
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestDumpLoadState(t *testing.T) {
+	nextID := 0
+	c, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "POST" {
+			nextID++
+			fmt.Fprintf(w, `{"ok": true, "id": %d, "venue": %q, "symbol": %q, "direction": "buy", "totalFilled": 4, "open": true}`, nextID, TestExchange, TestStock)
+			return
+		}
+
+		w.Header().Set("ETag", `"v1"`)
+		fmt.Fprintf(w, `{"ok": true, "symbol": %q, "bids": [{"price": 100, "qty": 10, "isBuy": true}], "ts": "2016-01-01T12:00:00Z"}`, TestStock)
+	})
+	defer server.Close()
+
+	c.RegisterStockInfo(TestExchange, TestStock, StockInfo{TickSize: 5, LotSize: 10})
+	for k := 0; k < 2; k++ {
+		if _, err := c.PlaceStockOrder(TestAccount, TestExchange, TestStock, 100, 10, "buy", "limit"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := c.GetStockOrderbook(TestExchange, TestStock); err != nil {
+		t.Fatal(err)
+	}
+
+	dump := &bytes.Buffer{}
+	if err := c.DumpState(dump); err != nil {
+		t.Fatal(err)
+	}
+	saved := dump.String()
+
+	loaded := &Client{}
+	if err := loaded.LoadState(dump); err != nil {
+		t.Fatal(err)
+	}
+
+	redump := &bytes.Buffer{}
+	if err := loaded.DumpState(redump); err != nil {
+		t.Fatal(err)
+	}
+	if redump.String() != saved {
+		t.Errorf("expected the loaded state to dump the same\nbefore: %s\nafter:  %s", saved, redump.String())
+	}
+
+	if position := loaded.Position(TestStock); position != 8 {
+		t.Errorf("expected a position of 8, got %d", position)
+	}
+	if info, err := loaded.StockInfo(TestExchange, TestStock); err != nil || info.TickSize != 5 {
+		t.Errorf("expected the stock info to carry over, got %+v, %v", info, err)
+	}
+	if len(loaded.orders) != 2 {
+		t.Errorf("expected 2 open orders, got %d", len(loaded.orders))
+	}
+
+	cached := loaded.cachedOrderbook(TestExchange, TestStock)
+	if cached == nil || cached.etag != `"v1"` || !cached.book.Timestamp.Equal(time.Date(2016, 1, 1, 12, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected the cached orderbook to carry over, got %+v", cached)
+	}
+}
@@ -0,0 +1,85 @@
+package starfighter
+
+import (
+	"math"
+	"time"
+)
+
+// FillRate is the fraction of the orders placed in the window before now
+// that got at least some of their qty filled. If you're always at 1 you're
+// probably paying too much; near 0 and you're not trading. No orders in
+// the window is 0.
+func FillRate(orders []OrderResultAlt, window time.Duration, now time.Time) float64 {
+	since := now.Add(-window)
+
+	placed, filled := 0, 0
+	for _, order := range orders {
+		if !order.Timestamp.After(since) || order.Timestamp.After(now) {
+			continue
+		}
+
+		placed++
+		if order.TotalFilled > 0 {
+			filled++
+		}
+	}
+
+	if placed == 0 {
+		return 0
+	}
+	return float64(filled) / float64(placed)
+}
+
+// ArrivalPriceSlippage is how much worse than the midpoint at the time you
+// decided to trade (arrivalQuote) the order's fills came in on average, in
+// cents per share, rounded. It's positive when it cost you: a buy filling
+// above the mid or a sell below it. It's false if nothing has filled or the
+// quote doesn't have both sides.
+func ArrivalPriceSlippage(order *OrderResult, arrivalQuote *StockQuote) (int, bool) {
+	price, ok := order.VWAP()
+	if !ok || arrivalQuote.Bid <= 0 || arrivalQuote.Ask <= 0 {
+		return 0, false
+	}
+
+	slippage := price - float64(arrivalQuote.Bid+arrivalQuote.Ask)/2
+	if order.Direction == Sell {
+		slippage = -slippage
+	}
+
+	return int(math.Round(slippage)), true
+}
+
+// SessionStats counts a client's orders over its life.
+type SessionStats struct {
+	// Orders placed successfully
+	OrdersPlaced int
+	// Orders seen to get at least one fill
+	OrdersFilled int
+}
+
+// OrderToTradeRatio is OrderToTradeRatio of the stats.
+func (s SessionStats) OrderToTradeRatio() float64 {
+	return OrderToTradeRatio(s.OrdersPlaced, s.OrdersFilled)
+}
+
+// OrderToTradeRatio is how many orders were placed for each one that filled.
+// Venues that charge for churn care about it. It's 0 with no orders, and
+// +Inf if none of them filled.
+func OrderToTradeRatio(placed, filled int) float64 {
+	if placed == 0 {
+		return 0
+	}
+	if filled == 0 {
+		return math.Inf(1)
+	}
+	return float64(placed) / float64(filled)
+}
+
+// InventorySkew is how many cents a market maker should move both its
+// quotes by to work its position back towards targetPosition: skewPerShare
+// for every share it's over or under. Long more than the target, it's
+// negative, so the quotes come down and sells get hit; short, the other
+// way round. At the target it's 0.
+func InventorySkew(position int, targetPosition int, skewPerShare int) int {
+	return (targetPosition - position) * skewPerShare
+}
@@ -0,0 +1,142 @@
+package starfighter
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestFillRate(t *testing.T) {
+	now := time.Date(2016, 1, 1, 12, 0, 0, 0, time.UTC)
+	ago := func(d time.Duration) time.Time { return now.Add(-d) }
+
+	orders := []OrderResultAlt{
+		{Timestamp: ago(time.Second), TotalFilled: 10},
+		{Timestamp: ago(10 * time.Second), TotalFilled: 0},
+		{Timestamp: ago(20 * time.Second), TotalFilled: 3},
+		{Timestamp: ago(50 * time.Second), TotalFilled: 0},
+		{Timestamp: ago(2 * time.Minute), TotalFilled: 5},    // too old
+		{Timestamp: ago(5 * time.Minute), TotalFilled: 0},    // too old
+		{Timestamp: now.Add(time.Second), TotalFilled: 1000}, // not yet
+	}
+
+	cases := []struct {
+		window time.Duration
+		rate   float64
+	}{
+		{time.Minute, 0.5},
+		{15 * time.Second, 0.5},
+		{5 * time.Second, 1},
+		{10 * time.Minute, 0.5},
+		{time.Millisecond, 0},
+	}
+
+	for _, c := range cases {
+		if rate := FillRate(orders, c.window, now); rate != c.rate {
+			t.Errorf("window %s: expected %v, got %v", c.window, c.rate, rate)
+		}
+	}
+
+	if rate := FillRate(nil, time.Minute, now); rate != 0 {
+		t.Errorf("expected 0 with no orders, got %v", rate)
+	}
+}
+
+func TestArrivalPriceSlippage(t *testing.T) {
+	arrival := &StockQuote{Bid: 100, Ask: 104}
+
+	cases := []struct {
+		direction Direction
+		fills     []Fill
+		slippage  int
+		ok        bool
+	}{
+		{Buy, []Fill{{Price: 104, Qty: 10}, {Price: 106, Qty: 10}}, 3, true},
+		{Buy, []Fill{{Price: 101, Qty: 10}}, -1, true},
+		{Sell, []Fill{{Price: 100, Qty: 30}, {Price: 97, Qty: 10}}, 3, true},
+		{Sell, []Fill{{Price: 105, Qty: 5}}, -3, true},
+		{Buy, []Fill{}, 0, false},
+	}
+
+	for _, c := range cases {
+		order := &OrderResult{Direction: c.direction, Fills: c.fills}
+		slippage, ok := ArrivalPriceSlippage(order, arrival)
+		if slippage != c.slippage || ok != c.ok {
+			t.Errorf("%s %v: expected (%d, %v), got (%d, %v)", c.direction, c.fills, c.slippage, c.ok, slippage, ok)
+		}
+	}
+
+	order := &OrderResult{Direction: Buy, Fills: []Fill{{Price: 100, Qty: 1}}}
+	if _, ok := ArrivalPriceSlippage(order, &StockQuote{Ask: 104}); ok {
+		t.Error("expected no answer without a bid at arrival")
+	}
+}
+
+func TestOrderToTradeRatio(t *testing.T) {
+	for _, c := range []struct {
+		placed, filled int
+		ratio          float64
+	}{
+		{10, 5, 2},
+		{3, 3, 1},
+		{0, 0, 0},
+		{4, 0, math.Inf(1)},
+	} {
+		if ratio := OrderToTradeRatio(c.placed, c.filled); ratio != c.ratio {
+			t.Errorf("%d/%d: expected %v, got %v", c.placed, c.filled, c.ratio, ratio)
+		}
+	}
+}
+
+func TestSessionStats(t *testing.T) {
+	m := NewMockVenue()
+	defer m.Close()
+	c := m.NewClient()
+
+	place := func(price, qty int64, direction Direction, orderType OrderType) *OrderResult {
+		order, err := c.PlaceStockOrder(TestAccount, TestExchange, TestStock, price, qty, string(direction), string(orderType))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return order
+	}
+
+	resting := place(100, 10, Sell, Limit)
+	place(90, 10, Buy, Limit)
+	place(100, 5, Buy, Limit)            // fills against the resting sell
+	place(95, 5, Buy, ImmediateOrCancel) // nothing there
+	place(100, 100, Buy, FillOrKill)     // not enough there
+	if _, err := c.GetOrderStatus(TestExchange, TestStock, int64(resting.ID)); err != nil {
+		t.Fatal(err)
+	}
+
+	stats := c.SessionStats()
+	if stats.OrdersPlaced != 5 || stats.OrdersFilled != 2 {
+		t.Errorf("expected 5 placed and 2 filled, got %+v", stats)
+	}
+	if ratio := stats.OrderToTradeRatio(); ratio != 2.5 {
+		t.Errorf("expected a ratio of 2.5, got %v", ratio)
+	}
+}
+
+func TestInventorySkew(t *testing.T) {
+	for _, c := range []struct {
+		name             string
+		position, target int
+		skew             int
+	}{
+		{"long", 100, 0, -100},
+		{"short", -50, 0, 50},
+		{"flat", 0, 0, 0},
+		{"at target", 30, 30, 0},
+		{"under target", 10, 30, 20},
+	} {
+		if skew := InventorySkew(c.position, c.target, 1); skew != c.skew {
+			t.Errorf("%s: expected %d, got %d", c.name, c.skew, skew)
+		}
+	}
+
+	if skew := InventorySkew(100, 0, 3); skew != -300 {
+		t.Errorf("expected 3 cents a share, got %d", skew)
+	}
+}
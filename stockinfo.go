@@ -0,0 +1,87 @@
+package starfighter
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrNoStockInfo is returned when nothing is known about a stock's increments.
+	ErrNoStockInfo = errors.New("starfighter: no stock info known")
+	// ErrOffTick is returned for order prices that aren't a multiple of the tick size.
+	ErrOffTick = errors.New("starfighter: price is not a multiple of the tick size")
+	// ErrOffLot is returned for order quantities that aren't a multiple of the lot size.
+	ErrOffLot = errors.New("starfighter: quantity is not a multiple of the lot size")
+)
+
+// StockInfo is the increments a stock trades in.
+type StockInfo struct {
+	// Smallest price increment, in cents
+	TickSize int64 `json:"tickSize"`
+	// Smallest quantity increment, in shares
+	LotSize int64 `json:"lotSize"`
+}
+
+// RoundPrice rounds the price onto the tick, never towards crossing the
+// spread: down for buys, up for sells.
+func (s *StockInfo) RoundPrice(price int64, direction Direction) int64 {
+	if s.TickSize <= 1 {
+		return price
+	}
+
+	rounded := price - price%s.TickSize
+	if direction == Sell && rounded != price {
+		rounded += s.TickSize
+	}
+	return rounded
+}
+
+// RoundQty rounds the quantity down onto the lot.
+func (s *StockInfo) RoundQty(qty int64) int64 {
+	if s.LotSize <= 1 {
+		return qty
+	}
+	return qty - qty%s.LotSize
+}
+
+// RegisterStockInfo tells the client what increments a stock trades in.
+// The API doesn't publish these (yet), so this is the only way it finds out.
+func (c *Client) RegisterStockInfo(venue, stock string, info StockInfo) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.stockInfo == nil {
+		c.stockInfo = map[string]StockInfo{}
+	}
+	c.stockInfo[venue+"/"+stock] = info
+}
+
+// StockInfo returns the increments a stock trades in, if they're known.
+func (c *Client) StockInfo(venue, stock string) (*StockInfo, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	info, ok := c.stockInfo[venue+"/"+stock]
+	if !ok {
+		return nil, ErrNoStockInfo
+	}
+	return &info, nil
+}
+
+// ValidateOrder checks the price and quantity against the stock's increments.
+// Orders for stocks with no known StockInfo always pass.
+func (c *Client) ValidateOrder(venue, stock string, price, qty int64) error {
+	info, err := c.StockInfo(venue, stock)
+	if err != nil {
+		return nil
+	}
+
+	if info.TickSize > 1 && price%info.TickSize != 0 {
+		return fmt.Errorf("%w: %d (tick %d)", ErrOffTick, price, info.TickSize)
+	}
+	if info.LotSize > 1 && qty%info.LotSize != 0 {
+		return fmt.Errorf("%w: %d (lot %d)", ErrOffLot, qty, info.LotSize)
+	}
+
+	return nil
+}
@@ -0,0 +1,46 @@
+package starfighter
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestValidateOrderOffTick(t *testing.T) {
+	c, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+	})
+	defer server.Close()
+
+	if err := c.ValidateOrder(TestExchange, TestStock, 5001, 7); err != nil {
+		t.Errorf("expected unknown stocks to pass, got %v", err)
+	}
+
+	c.RegisterStockInfo(TestExchange, TestStock, StockInfo{TickSize: 5, LotSize: 10})
+
+	if err := c.ValidateOrder(TestExchange, TestStock, 5000, 100); err != nil {
+		t.Errorf("expected on-tick order to pass, got %v", err)
+	}
+	if err := c.ValidateOrder(TestExchange, TestStock, 5000, 105); !errors.Is(err, ErrOffLot) {
+		t.Errorf("expected ErrOffLot, got %v", err)
+	}
+
+	_, err := c.PlaceStockOrder(TestAccount, TestExchange, TestStock, 5001, 100, string(Buy), string(Limit))
+	if !errors.Is(err, ErrOffTick) {
+		t.Errorf("expected ErrOffTick, got %v", err)
+	}
+}
+
+func TestStockInfoRounding(t *testing.T) {
+	info := &StockInfo{TickSize: 5, LotSize: 10}
+
+	if price := info.RoundPrice(5003, Buy); price != 5000 {
+		t.Errorf("expected buy to round down to 5000, got %d", price)
+	}
+	if price := info.RoundPrice(5003, Sell); price != 5005 {
+		t.Errorf("expected sell to round up to 5005, got %d", price)
+	}
+	if qty := info.RoundQty(109); qty != 100 {
+		t.Errorf("expected 100, got %d", qty)
+	}
+}
@@ -2,10 +2,22 @@ package starfighter
 
 import "time"
 
+// Direction is the side of the book an order is on.
+type Direction string
+
+const (
+	// Buy is for buying stock.
+	Buy Direction = "buy"
+	// Sell is for selling stock.
+	Sell Direction = "sell"
+)
+
 // Stock represents a symbol on the venue.
 type Stock struct {
-	Name   string
-	Symbol string
+	Name   string `json:"name"`
+	Symbol string `json:"symbol"`
+	// The API leaves this out; ListAllStocks fills it in
+	Venue string `json:"venue,omitempty"`
 }
 
 // StockQuote shows a quote for a stock.
@@ -24,28 +36,37 @@ type StockQuote struct {
 	QuoteAt   time.Time `json:"quoteTime"`
 }
 
+// BookEntry is an order resting on one side of an OrderBook.
+type BookEntry struct {
+	IsBuy bool `json:"isBuy"`
+	Price int  `json:"price"`
+	Qty   int  `json:"qty"`
+}
+
 // OrderBook represents the current state of an order.
 type OrderBook struct {
-	Asks []struct {
-		IsBuy bool `json:"isBuy"`
-		Price int  `json:"price"`
-		Qty   int  `json:"qty"`
-	} `json:"asks"`
-	Bids []struct {
-		IsBuy bool `json:"isBuy"`
-		Price int  `json:"price"`
-		Qty   int  `json:"qty"`
-	} `json:"bids"`
-	Symbol    string    `json:"symbol"`
+	Asks      []BookEntry `json:"asks"`
+	Bids      []BookEntry `json:"bids"`
+	Symbol    string      `json:"symbol"`
+	Timestamp time.Time   `json:"ts"`
+	Venue     string      `json:"venue"`
+}
+
+// Fill is a single execution against an order.
+type Fill struct {
+	Price     int       `json:"price"`
+	Qty       int       `json:"qty"`
 	Timestamp time.Time `json:"ts"`
-	Venue     string    `json:"venue"`
+	// The API doesn't send this with fills, so set it from the order
+	// if you need it (PnL does).
+	Direction Direction `json:"direction,omitempty"`
 }
 
 // OrderResult details the result of an order.
 type OrderResult struct {
 	Symbol      string    `json:"symbol"`
 	Venue       string    `json:"venue"`
-	Direction   string    `json:"direction"`
+	Direction   Direction `json:"direction"`
 	OriginalQty int       `json:"originalQty"`
 	Qty         int       `json:"qty"`
 	Price       int       `json:"price"`
@@ -53,13 +74,9 @@ type OrderResult struct {
 	ID          int       `json:"id"`
 	Account     string    `json:"account"`
 	Timestamp   time.Time `json:"ts"`
-	Fills       []struct {
-		Price     int       `json:"price"`
-		Qty       int       `json:"qty"`
-		Timestamp time.Time `json:"ts"`
-	} `json:"fills"`
-	TotalFilled int  `json:"totalFilled"`
-	Open        bool `json:"open"`
+	Fills       []Fill    `json:"fills"`
+	TotalFilled int       `json:"totalFilled"`
+	Open        bool      `json:"open"`
 }
 
 // OrderResultAlt is the same thing as OrderResult, except uses orderType instead of type.
@@ -67,7 +84,7 @@ type OrderResult struct {
 type OrderResultAlt struct {
 	Symbol      string    `json:"symbol"`
 	Venue       string    `json:"venue"`
-	Direction   string    `json:"direction"`
+	Direction   Direction `json:"direction"`
 	OriginalQty int       `json:"originalQty"`
 	Qty         int       `json:"qty"`
 	Price       int       `json:"price"`
@@ -75,16 +92,44 @@ type OrderResultAlt struct {
 	ID          int       `json:"id"`
 	Account     string    `json:"account"`
 	Timestamp   time.Time `json:"ts"`
-	Fills       []struct {
-		Price     int       `json:"price"`
-		Qty       int       `json:"qty"`
-		Timestamp time.Time `json:"ts"`
-	} `json:"fills"`
-	TotalFilled int  `json:"totalFilled"`
-	Open        bool `json:"open"`
+	Fills       []Fill    `json:"fills"`
+	TotalFilled int       `json:"totalFilled"`
+	Open        bool      `json:"open"`
 }
 
 // OrderResultList shows a list of orders.
 type OrderResultList struct {
 	Orders []OrderResultAlt `json:"orders"`
+	// Orders that were in the list but were left out, and why: ones that
+	// couldn't be decoded or were for the wrong account
+	Warnings []error `json:"-"`
+}
+
+// Execution is a fill notification from the executions feed.
+type Execution struct {
+	Account          string         `json:"account"`
+	Venue            string         `json:"venue"`
+	Symbol           string         `json:"symbol"`
+	Order            OrderResultAlt `json:"order"`
+	StandingID       int            `json:"standingId"`
+	IncomingID       int            `json:"incomingId"`
+	Price            int            `json:"price"`
+	Filled           int            `json:"filled"`
+	FilledAt         time.Time      `json:"filledAt"`
+	StandingComplete bool           `json:"standingComplete"`
+	IncomingComplete bool           `json:"incomingComplete"`
 }
+
+// OrderType is how an order should be executed.
+type OrderType string
+
+const (
+	// Limit orders rest on the book until filled or cancelled.
+	Limit OrderType = "limit"
+	// Market orders take whatever price they can get.
+	Market OrderType = "market"
+	// FillOrKill orders fill completely and immediately, or not at all.
+	FillOrKill OrderType = "fill-or-kill"
+	// ImmediateOrCancel orders fill what they can immediately and cancel the rest.
+	ImmediateOrCancel OrderType = "immediate-or-cancel"
+)
@@ -0,0 +1,82 @@
+package starfighter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// apiStatus is the part of every response that says whether it worked.
+type apiStatus struct {
+	OK       *bool  `json:"ok"`
+	Error    string `json:"error"`
+	ResumeAt string `json:"resumeAt"`
+}
+
+// ListVenueOrderStatusUnbuffered is ListVenueOrderStatus, but decodes the
+// orders straight off the wire instead of via Call, which decodes the whole
+// response into a map and keeps a copy of it on the side. That adds up for
+// accounts with a lot of orders.
+func (c *Client) ListVenueOrderStatusUnbuffered(ctx context.Context, venue, account string) (*OrderResultList, error) {
+	return c.streamOrderList(ctx, account, fmt.Sprintf("/venues/%s/accounts/%s/orders", venue, account))
+}
+
+// ListVenueStockOrderStatusUnbuffered is the same for
+// ListVenueStockOrderStatus.
+func (c *Client) ListVenueStockOrderStatusUnbuffered(ctx context.Context, venue, stock, account string) (*OrderResultList, error) {
+	return c.streamOrderList(ctx, account, fmt.Sprintf("/venues/%s/accounts/%s/stocks/%s/orders", venue, account, stock))
+}
+
+func (c *Client) streamOrderList(ctx context.Context, account, endpoint string) (*OrderResultList, error) {
+	response := struct {
+		apiStatus
+		Orders []json.RawMessage `json:"orders"`
+	}{}
+
+	if err := c.stream(ctx, "GET", endpoint, &response, &response.apiStatus); err != nil {
+		return nil, err
+	}
+
+	list := OrderResultList{}
+	list.Orders, list.Warnings = decodeOrders(response.Orders)
+	list.dropOtherAccounts(account)
+	return &list, nil
+}
+
+// stream makes the call and decodes the response directly into v, which
+// should have status embedded in it so it gets decoded along the way. Errors
+// come back the same as from Call.
+func (c *Client) stream(ctx context.Context, method, endpoint string, v interface{}, status *apiStatus) error {
+	req, err := c.newRequest(ctx, method, endpoint, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.do(c.httpClient(method), req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	err = json.NewDecoder(c.limitBody(resp.Body)).Decode(v)
+	if err != nil && err != io.EOF {
+		return err
+	}
+
+	var apiErr *APIError
+	if err == io.EOF {
+		if resp.StatusCode >= http.StatusBadRequest {
+			apiErr = newAPIError(resp.StatusCode, http.StatusText(resp.StatusCode))
+		}
+	} else if status.OK != nil && !*status.OK {
+		apiErr = newAPIError(resp.StatusCode, status.Error)
+	}
+
+	if apiErr == nil || (c.IsBenignError != nil && c.IsBenignError(apiErr)) {
+		return nil
+	}
+
+	return maintenanceError(apiErr, resp, status.ResumeAt)
+}
@@ -0,0 +1,76 @@
+package starfighter
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// orderListJSON is a list of n orders with a couple of fills each.
+func orderListJSON(n int) string {
+	orders := make([]string, n)
+	for k := range orders {
+		orders[k] = fmt.Sprintf(`{"id": %d, "symbol": %q, "venue": %q, "direction": "buy", "originalQty": 100, "qty": 40, "price": 5000, "orderType": "limit", "account": %q, "ts": "2016-01-01T12:00:00Z", "fills": [{"price": 5000, "qty": 30, "ts": "2016-01-01T12:00:01Z"}, {"price": 4999, "qty": 30, "ts": "2016-01-01T12:00:02Z"}], "totalFilled": 60, "open": true}`,
+			k, TestStock, TestExchange, TestAccount)
+	}
+	return fmt.Sprintf(`{"ok": true, "venue": %q, "orders": [%s]}`, TestExchange, strings.Join(orders, ","))
+}
+
+func TestListVenueOrderStatusUnbuffered(t *testing.T) {
+	body := orderListJSON(3)
+	c, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, "DOWNEX") {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"ok": false, "error": "No venue exists with the symbol DOWNEX"}`)
+			return
+		}
+		fmt.Fprint(w, body)
+	})
+	defer server.Close()
+
+	buffered, err := c.ListVenueOrderStatus(TestExchange, TestAccount)
+	if err != nil {
+		t.Fatal(err)
+	}
+	streamed, err := c.ListVenueOrderStatusUnbuffered(context.Background(), TestExchange, TestAccount)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(streamed.Orders) != 3 || fmt.Sprint(streamed) != fmt.Sprint(buffered) {
+		t.Errorf("expected the same orders either way, got\n%+v\n%+v", buffered, streamed)
+	}
+
+	_, err = c.ListVenueStockOrderStatusUnbuffered(context.Background(), "DOWNEX", TestStock, TestAccount)
+	if apiErr, ok := err.(*APIError); !ok || apiErr.Code != http.StatusNotFound || apiErr.ErrorCode != ErrCodeNotFound {
+		t.Errorf("expected a not found APIError, got %v", err)
+	}
+}
+
+func BenchmarkListVenueOrderStatus(b *testing.B) {
+	body := orderListJSON(2000)
+	c, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, body)
+	})
+	defer server.Close()
+
+	b.Run("Buffered", func(b *testing.B) {
+		b.ReportAllocs()
+		for k := 0; k < b.N; k++ {
+			if _, err := c.ListVenueOrderStatus(TestExchange, TestAccount); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("Unbuffered", func(b *testing.B) {
+		b.ReportAllocs()
+		for k := 0; k < b.N; k++ {
+			if _, err := c.ListVenueOrderStatusUnbuffered(context.Background(), TestExchange, TestAccount); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,48 @@
+package starfighter
+
+import (
+	"sort"
+	"time"
+)
+
+// TimelineEventKind is what happened to an order in a TimelineEvent.
+type TimelineEventKind string
+
+const (
+	// OrderPlaced is the order going in.
+	OrderPlaced TimelineEventKind = "placed"
+	// OrderFilled is one of its fills.
+	OrderFilled TimelineEventKind = "fill"
+	// OrderClosed is it being done, filled or cancelled.
+	OrderClosed TimelineEventKind = "closed"
+)
+
+// TimelineEvent is one thing that happened to an order. Price and Qty are
+// set for fills, and for placing, where they're what was asked for.
+type TimelineEvent struct {
+	Kind  TimelineEventKind
+	At    time.Time
+	Price int
+	Qty   int
+}
+
+// Timeline is the order's history, oldest first: placed, each fill, then
+// closed if it's not open any more. The API doesn't say when an order was
+// cancelled, so closing is put at the last thing we know happened to it.
+func (o *OrderResultAlt) Timeline() []TimelineEvent {
+	events := []TimelineEvent{{Kind: OrderPlaced, At: o.Timestamp, Price: o.Price, Qty: o.OriginalQty}}
+
+	fills := append([]Fill(nil), o.Fills...)
+	sort.SliceStable(fills, func(i, j int) bool {
+		return fills[i].Timestamp.Before(fills[j].Timestamp)
+	})
+	for _, fill := range fills {
+		events = append(events, TimelineEvent{Kind: OrderFilled, At: fill.Timestamp, Price: fill.Price, Qty: fill.Qty})
+	}
+
+	if !o.Open {
+		events = append(events, TimelineEvent{Kind: OrderClosed, At: events[len(events)-1].At})
+	}
+
+	return events
+}
@@ -0,0 +1,43 @@
+package starfighter
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestTimeline(t *testing.T) {
+	start := time.Date(2016, 1, 1, 12, 0, 0, 0, time.UTC)
+	at := func(seconds int) time.Time {
+		return start.Add(time.Duration(seconds) * time.Second)
+	}
+
+	order := &OrderResultAlt{
+		Price:       100,
+		OriginalQty: 30,
+		Timestamp:   start,
+		Fills: []Fill{
+			{Price: 100, Qty: 10, Timestamp: at(5)},
+			{Price: 99, Qty: 5, Timestamp: at(2)},
+			{Price: 100, Qty: 15, Timestamp: at(9)},
+		},
+		Open: false,
+	}
+
+	expected := []TimelineEvent{
+		{OrderPlaced, start, 100, 30},
+		{OrderFilled, at(2), 99, 5},
+		{OrderFilled, at(5), 100, 10},
+		{OrderFilled, at(9), 100, 15},
+		{OrderClosed, at(9), 0, 0},
+	}
+	if events := order.Timeline(); !reflect.DeepEqual(events, expected) {
+		t.Errorf("expected %+v, got %+v", expected, events)
+	}
+
+	// still open: no closing event
+	order.Open = true
+	if events := order.Timeline(); len(events) != 4 || events[3].Kind != OrderFilled {
+		t.Errorf("expected to end on the last fill, got %+v", events)
+	}
+}
@@ -0,0 +1,154 @@
+package starfighter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// trackedFeed is a websocket subscription the client has open. cancel ends
+// it and done is closed once its goroutine has exited.
+type trackedFeed struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// trackOrder remembers an order placed through the client, or updates what
+// we know about one, so Shutdown knows what's still open. Orders we didn't
+// place are only updated, never added. Whatever's been filled since we last
+// saw the order goes into the position for its symbol.
+func (c *Client) trackOrder(order OrderResultAlt, placed bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	prev, ok := c.orders[order.ID]
+	if !ok && !placed {
+		return
+	}
+
+	if order.Symbol == "" {
+		order.Venue, order.Symbol = prev.Venue, prev.Symbol
+	}
+	if order.Direction == "" {
+		order.Direction = prev.Direction
+	}
+
+	if placed {
+		c.session.OrdersPlaced++
+	}
+	if prev.TotalFilled == 0 && order.TotalFilled > 0 {
+		c.session.OrdersFilled++
+	}
+
+	if filled := int64(order.TotalFilled - prev.TotalFilled); filled != 0 {
+		if order.Direction == Sell {
+			filled = -filled
+		}
+		if c.positions == nil {
+			c.positions = map[string]int64{}
+		}
+		c.positions[order.Symbol] += filled
+	}
+
+	if !order.Open {
+		delete(c.orders, order.ID)
+		return
+	}
+
+	if c.orders == nil {
+		c.orders = map[int]OrderResultAlt{}
+	}
+	c.orders[order.ID] = order
+}
+
+// untrackOrder forgets an order, e.g. when a cancel came back empty.
+func (c *Client) untrackOrder(id int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.orders, id)
+}
+
+// SessionStats is how many orders the client has placed, and how many of
+// those it has seen get a fill.
+func (c *Client) SessionStats() SessionStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.session
+}
+
+// Position is the number of shares of the symbol the client has seen its
+// orders fill, negative if short. It only knows about fills it has seen,
+// i.e. in the responses to placing, checking on and cancelling orders.
+func (c *Client) Position(symbol string) int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.positions[symbol]
+}
+
+// trackFeed wraps ctx so the feed, or anything else left running in the
+// background, can be stopped by Shutdown. Call the returned function once
+// its goroutine is done.
+func (c *Client) trackFeed(ctx context.Context) (context.Context, func()) {
+	ctx, cancel := context.WithCancel(ctx)
+	feed := &trackedFeed{cancel: cancel, done: make(chan struct{})}
+
+	c.mu.Lock()
+	if c.feeds == nil {
+		c.feeds = map[*trackedFeed]struct{}{}
+	}
+	c.feeds[feed] = struct{}{}
+	c.mu.Unlock()
+
+	return ctx, func() {
+		c.mu.Lock()
+		delete(c.feeds, feed)
+		c.mu.Unlock()
+
+		cancel()
+		close(feed.done)
+	}
+}
+
+// Shutdown cancels every open order placed through the client and closes
+// every feed it has open, for when your bot is told to stop. It waits for
+// the feeds to go away until ctx is done. Everything that went wrong is
+// returned together.
+func (c *Client) Shutdown(ctx context.Context) error {
+	type venueStock struct{ venue, stock string }
+
+	c.mu.Lock()
+	orders := map[venueStock][]int64{}
+	for id, order := range c.orders {
+		key := venueStock{order.Venue, order.Symbol}
+		orders[key] = append(orders[key], int64(id))
+	}
+	feeds := make([]*trackedFeed, 0, len(c.feeds))
+	for feed := range c.feeds {
+		feeds = append(feeds, feed)
+	}
+	c.mu.Unlock()
+
+	for _, feed := range feeds {
+		feed.cancel()
+	}
+
+	var errs []error
+	for key, ids := range orders {
+		_, cancelErrs := c.CancelOrders(ctx, key.venue, key.stock, ids)
+		for k, err := range cancelErrs {
+			if err != nil {
+				errs = append(errs, fmt.Errorf("starfighter: cancelling order %d: %w", ids[k], err))
+			}
+		}
+	}
+
+	for _, feed := range feeds {
+		select {
+		case <-feed.done:
+		case <-ctx.Done():
+			return errors.Join(append(errs, ctx.Err())...)
+		}
+	}
+
+	return errors.Join(errs...)
+}
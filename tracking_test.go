@@ -0,0 +1,70 @@
+package starfighter
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"path"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestShutdown(t *testing.T) {
+	var mu sync.Mutex
+	nextID := 0
+	cancelled := map[string]bool{}
+
+	c, server := newFeedServer(t, nil, nil, nil, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		switch r.Method {
+		case "POST":
+			nextID++
+			fmt.Fprintf(w, `{"ok": true, "id": %d, "venue": %q, "symbol": %q, "open": true}`, nextID, TestExchange, TestStock)
+		case "DELETE":
+			cancelled[r.URL.Path] = true
+			fmt.Fprintf(w, `{"ok": true, "id": %s, "open": false}`, path.Base(r.URL.Path))
+		}
+	})
+	defer server.Close()
+
+	for k := 0; k < 2; k++ {
+		if _, err := c.PlaceStockOrder(TestAccount, TestExchange, TestStock, 100, 10, "buy", "limit"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	quotes, errs, err := c.SubscribeQuotes(context.Background(), TestAccount, TestExchange)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	if err := c.Shutdown(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	for id := 1; id <= 2; id++ {
+		order := fmt.Sprintf("/venues/%s/stocks/%s/orders/%d", TestExchange, TestStock, id)
+		if !cancelled[order] {
+			t.Errorf("expected order %d to be cancelled", id)
+		}
+	}
+
+	if _, ok := <-quotes; ok {
+		t.Error("expected quotes to close on shutdown")
+	}
+	if err, ok := <-errs; ok {
+		t.Errorf("expected a clean shutdown, got %v", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.orders) != 0 || len(c.feeds) != 0 {
+		t.Errorf("expected nothing left open, got %d orders and %d feeds", len(c.orders), len(c.feeds))
+	}
+}
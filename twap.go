@@ -0,0 +1,161 @@
+package starfighter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// twapSample is a last price and when the tickertape said so.
+type twapSample struct {
+	at    time.Time
+	price int
+}
+
+// TWAPTracker keeps the time-weighted average of a stock's last trade
+// price, as a benchmark for TWAP strategies: each price counts for as long
+// as it stood, until the next quote. Feed it from the tickertape; it's safe
+// to do so from several goroutines.
+type TWAPTracker struct {
+	mu        sync.Mutex
+	maxWindow time.Duration
+	samples   []twapSample
+}
+
+// NewTWAPTracker creates a TWAPTracker that can average over windows of up
+// to maxWindow; anything older is let go.
+func NewTWAPTracker(maxWindow time.Duration) *TWAPTracker {
+	return &TWAPTracker{maxWindow: maxWindow}
+}
+
+// AddQuote adds the quote's last price as of its QuoteAt. Quotes with no
+// last trade or time, or older than the latest, are skipped.
+func (t *TWAPTracker) AddQuote(quote StockQuote) {
+	if quote.Last <= 0 || quote.QuoteAt.IsZero() {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if n := len(t.samples); n > 0 && quote.QuoteAt.Before(t.samples[n-1].at) {
+		return
+	}
+	t.samples = append(t.samples, twapSample{quote.QuoteAt, quote.Last})
+
+	// keep the sample that was standing at the start of the longest window
+	since := quote.QuoteAt.Add(-t.maxWindow)
+	drop := 0
+	for drop+1 < len(t.samples) && !t.samples[drop+1].at.After(since) {
+		drop++
+	}
+	t.samples = t.samples[drop:]
+}
+
+// TWAP is the time-weighted average over the window leading up to the
+// latest quote, which is capped at the tracker's maxWindow. If the quotes
+// don't go back that far, it's over the time they do cover; if that's no
+// time at all, it's the latest price. With no quotes it's 0.
+func (t *TWAPTracker) TWAP(window time.Duration) float64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	n := len(t.samples)
+	if n == 0 {
+		return 0
+	}
+
+	end := t.samples[n-1].at
+	since := end.Add(-min(window, t.maxWindow))
+
+	weighted, total := 0.0, time.Duration(0)
+	for k := 0; k < n-1; k++ {
+		from, to := t.samples[k].at, t.samples[k+1].at
+		if !to.After(since) {
+			continue
+		}
+		if from.Before(since) {
+			from = since
+		}
+
+		weighted += float64(t.samples[k].price) * float64(to.Sub(from))
+		total += to.Sub(from)
+	}
+
+	if total == 0 {
+		return float64(t.samples[n-1].price)
+	}
+	return weighted / float64(total)
+}
+
+// ExecuteTWAP works a totalQty order in direction dir into the market over
+// duration, as slices child orders spread evenly across it: the first right
+// away, the rest duration/slices apart. Each is an immediate-or-cancel at
+// the touch on the other side (the best ask, for a buy), so whatever doesn't
+// fill there is dropped rather than chased. Slices that can't be placed are
+// skipped and their errors returned along with the results of the rest. If
+// ctx is done mid-schedule, it stops there and returns what it placed.
+func (c *Client) ExecuteTWAP(ctx context.Context, account, venue, stock string, totalQty int64, dir Direction, duration time.Duration, slices int) ([]OrderResult, error) {
+	if slices <= 0 || totalQty <= 0 {
+		return nil, fmt.Errorf("starfighter: can't split %d shares into %d slices", totalQty, slices)
+	}
+
+	clk := c.clock()
+	start := clk.Now()
+	interval := duration / time.Duration(slices)
+
+	results := []OrderResult{}
+	var errs []error
+
+	for k := 0; k < slices; k++ {
+		if wait := start.Add(time.Duration(k) * interval).Sub(clk.Now()); wait > 0 {
+			select {
+			case <-clk.After(wait):
+			case <-ctx.Done():
+				return results, ctx.Err()
+			}
+		}
+		if ctx.Err() != nil {
+			return results, ctx.Err()
+		}
+
+		// spread the remainder over the first few
+		qty := totalQty / int64(slices)
+		if int64(k) < totalQty%int64(slices) {
+			qty++
+		}
+		if qty == 0 {
+			continue
+		}
+
+		order, err := c.placeAtTouch(ctx, account, venue, stock, qty, dir)
+		if err != nil {
+			if ctx.Err() != nil {
+				return results, ctx.Err()
+			}
+			errs = append(errs, fmt.Errorf("starfighter: TWAP slice %d: %w", k, err))
+			continue
+		}
+		results = append(results, *order)
+	}
+
+	return results, errors.Join(errs...)
+}
+
+// placeAtTouch sends an immediate-or-cancel at the best price on the other
+// side of the book.
+func (c *Client) placeAtTouch(ctx context.Context, account, venue, stock string, qty int64, dir Direction) (*OrderResult, error) {
+	book, err := c.getStockOrderbook(ctx, venue, stock)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := book.against(dir)
+	if len(entries) == 0 {
+		return nil, ErrNoLiquidity
+	}
+
+	return c.placeStockOrder(ctx, account, venue, stock, int64(entries[0].Price), qty, string(dir), string(ImmediateOrCancel))
+}
@@ -0,0 +1,151 @@
+package starfighter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestTWAPTracker(t *testing.T) {
+	start := time.Date(2016, 1, 1, 12, 0, 0, 0, time.UTC)
+	tracker := NewTWAPTracker(time.Minute)
+
+	if twap := tracker.TWAP(time.Minute); twap != 0 {
+		t.Errorf("expected 0 with no quotes, got %v", twap)
+	}
+
+	tracker.AddQuote(StockQuote{Last: 100, QuoteAt: start})
+	if twap := tracker.TWAP(time.Minute); twap != 100 {
+		t.Errorf("expected the only price, got %v", twap)
+	}
+
+	// 100 for 10s, 110 for 30s, 90 for 20s, then 120 as of 60s
+	tracker.AddQuote(StockQuote{Last: 110, QuoteAt: start.Add(10 * time.Second)})
+	tracker.AddQuote(StockQuote{Last: 0, QuoteAt: start.Add(15 * time.Second)}) // no trade yet, skipped
+	tracker.AddQuote(StockQuote{Last: 90, QuoteAt: start.Add(40 * time.Second)})
+	tracker.AddQuote(StockQuote{Last: 120, QuoteAt: start.Add(60 * time.Second)})
+
+	for _, c := range []struct {
+		window time.Duration
+		twap   float64
+	}{
+		{time.Minute, (100*10 + 110*30 + 90*20) / 60.0},
+		{30 * time.Second, (110*10 + 90*20) / 30.0},
+		{10 * time.Second, 90},
+		{time.Hour, (100*10 + 110*30 + 90*20) / 60.0},
+	} {
+		if twap := tracker.TWAP(c.window); math.Abs(twap-c.twap) > 1e-9 {
+			t.Errorf("window %s: expected %v, got %v", c.window, c.twap, twap)
+		}
+	}
+
+	// 30s later the first 100 has fallen out of the longest window
+	tracker.AddQuote(StockQuote{Last: 120, QuoteAt: start.Add(90 * time.Second)})
+	expected := (110*10 + 90*20 + 120*30) / 60.0
+	if twap := tracker.TWAP(time.Hour); math.Abs(twap-expected) > 1e-9 {
+		t.Errorf("expected %v once the oldest price is let go, got %v", expected, twap)
+	}
+}
+
+// fakeClock's time only moves when something waits on it, and then it
+// jumps straight to the end of the wait. With block set, waits never end.
+type fakeClock struct {
+	mu    sync.Mutex
+	now   time.Time
+	waits []time.Duration
+	block bool
+}
+
+func (f *fakeClock) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.now
+}
+
+func (f *fakeClock) After(d time.Duration) <-chan time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.waits = append(f.waits, d)
+	ch := make(chan time.Time, 1)
+	if !f.block {
+		f.now = f.now.Add(d)
+		ch <- f.now
+	}
+	return ch
+}
+
+func TestExecuteTWAP(t *testing.T) {
+	start := time.Date(2016, 1, 1, 12, 0, 0, 0, time.UTC)
+	clk := &fakeClock{now: start}
+
+	type placement struct {
+		at    time.Duration
+		order orderRequest
+	}
+	var placed []placement
+
+	c, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "POST" {
+			order := orderRequest{}
+			json.NewDecoder(r.Body).Decode(&order)
+			placed = append(placed, placement{clk.Now().Sub(start), order})
+			fmt.Fprintf(w, `{"ok": true, "id": %d, "qty": %d}`, len(placed), order.Qty)
+			return
+		}
+		fmt.Fprint(w, `{"ok": true, "bids": [{"price": 99, "qty": 1000, "isBuy": true}], "asks": [{"price": 101, "qty": 1000}, {"price": 102, "qty": 1000}]}`)
+	})
+	defer server.Close()
+	c.timeSource = clk
+
+	results, err := c.ExecuteTWAP(context.Background(), TestAccount, TestExchange, TestStock, 100, Buy, time.Minute, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(results) != 3 || len(placed) != 3 {
+		t.Fatalf("expected 3 slices, got %d (%d placed)", len(results), len(placed))
+	}
+	for k, e := range []struct {
+		at  time.Duration
+		qty int64
+	}{{0, 34}, {20 * time.Second, 33}, {40 * time.Second, 33}} {
+		p := placed[k]
+		if p.at != e.at || p.order.Qty != e.qty {
+			t.Errorf("slice %d: expected %d at %s, got %d at %s", k, e.qty, e.at, p.order.Qty, p.at)
+		}
+		if p.order.Price != 101 || p.order.OrderType != string(ImmediateOrCancel) || p.order.Direction != string(Buy) {
+			t.Errorf("slice %d: expected an IOC buy at the ask, got %+v", k, p.order)
+		}
+	}
+}
+
+func TestExecuteTWAPCancel(t *testing.T) {
+	clk := &fakeClock{now: time.Now(), block: true}
+
+	c, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "POST" {
+			fmt.Fprint(w, `{"ok": true, "id": 1}`)
+			return
+		}
+		fmt.Fprint(w, `{"ok": true, "bids": [{"price": 99, "qty": 1000, "isBuy": true}]}`)
+	})
+	defer server.Close()
+	c.timeSource = clk
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	results, err := c.ExecuteTWAP(ctx, TestAccount, TestExchange, TestStock, 100, Sell, time.Minute, 4)
+	if err != context.DeadlineExceeded {
+		t.Errorf("expected the deadline to stop it, got %v", err)
+	}
+	if len(results) != 1 {
+		t.Errorf("expected just the first slice, got %d", len(results))
+	}
+}
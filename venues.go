@@ -0,0 +1,240 @@
+package starfighter
+
+import (
+	"context"
+	"sync"
+)
+
+// eachVenue calls fetch for every one of the venues at once, with k the
+// venue's index in venues, and collects the errors by venue. With FailFast
+// set, the first venue to fail cancels the rest, and failed is true with
+// only its error collected.
+func (c *Client) eachVenue(ctx context.Context, venues []string, fetch func(ctx context.Context, k int, venue string) error) (errs VenueErrors, failed bool) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	errs = VenueErrors{}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for k, venue := range venues {
+		wg.Add(1)
+		go func(k int, venue string) {
+			defer wg.Done()
+
+			if err := fetch(ctx, k, venue); err != nil {
+				mu.Lock()
+				if !failed {
+					errs[venue] = err
+					if c.FailFast {
+						failed = true
+						cancel()
+					}
+				}
+				mu.Unlock()
+			}
+		}(k, venue)
+	}
+
+	wg.Wait()
+
+	return errs, failed
+}
+
+// AllOpenOrders lists the account's open orders on every one of the venues,
+// asking them all at once. If some venues fail, the open orders from the
+// rest are still returned, along with a VenueErrors saying what went wrong.
+// With FailFast set, the first venue to fail cancels the rest instead, and
+// only its error and no orders are returned.
+func (c *Client) AllOpenOrders(ctx context.Context, account string, venues []string) ([]OrderResultAlt, error) {
+	results := make([][]OrderResultAlt, len(venues))
+
+	errs, failed := c.eachVenue(ctx, venues, func(ctx context.Context, k int, venue string) error {
+		list, err := c.listVenueOrderStatus(ctx, venue, account)
+		if err != nil {
+			return err
+		}
+
+		for _, order := range list.Orders {
+			if order.Open {
+				results[k] = append(results[k], order)
+			}
+		}
+		return nil
+	})
+
+	if failed {
+		return nil, errs
+	}
+
+	open := []OrderResultAlt{}
+	for _, orders := range results {
+		open = append(open, orders...)
+	}
+
+	if len(errs) > 0 {
+		return open, errs
+	}
+
+	return open, nil
+}
+
+// ListAllStocks lists the stocks on every one of the venues, asking them all
+// at once, with each Stock's Venue set. A stock a venue lists twice is only
+// in there once. Errors work as they do for AllOpenOrders.
+func (c *Client) ListAllStocks(ctx context.Context, venues []string) ([]Stock, error) {
+	results := make([][]Stock, len(venues))
+
+	errs, failed := c.eachVenue(ctx, venues, func(ctx context.Context, k int, venue string) error {
+		stocks, err := c.listVenueStocks(ctx, venue)
+		if err != nil {
+			return err
+		}
+
+		for _, stock := range stocks {
+			stock.Venue = venue
+			results[k] = append(results[k], stock)
+		}
+		return nil
+	})
+
+	if failed {
+		return nil, errs
+	}
+
+	type venueStock struct{ venue, symbol string }
+	seen := map[venueStock]bool{}
+
+	all := []Stock{}
+	for _, stocks := range results {
+		for _, stock := range stocks {
+			key := venueStock{stock.Venue, stock.Symbol}
+			if !seen[key] {
+				seen[key] = true
+				all = append(all, stock)
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return all, errs
+	}
+
+	return all, nil
+}
+
+// GetVenueOrderbooks fetches the orderbooks of the venue's stocks, a few at
+// a time. The first fetch to fail stops the rest, as does ctx being done,
+// and no new fetches are started after that; the books already fetched are
+// returned along with the error.
+func (c *Client) GetVenueOrderbooks(ctx context.Context, venue string, stocks []string) (map[string]*OrderBook, error) {
+	parent := ctx
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	books := make(map[string]*OrderBook, len(stocks))
+	var firstErr error
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	jobs := make(chan string)
+
+	for k := 0; k < min(batchConcurrency, len(stocks)); k++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for stock := range jobs {
+				book, err := c.getStockOrderbook(ctx, venue, stock)
+
+				mu.Lock()
+				if err != nil {
+					if firstErr == nil {
+						firstErr = err
+						cancel()
+					}
+				} else {
+					books[stock] = book
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+send:
+	for _, stock := range stocks {
+		select {
+		case jobs <- stock:
+		case <-ctx.Done():
+			break send
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	// whatever the fetches said, a cancelled ctx is why they said it
+	if err := parent.Err(); err != nil {
+		return books, err
+	}
+	return books, firstErr
+}
+
+// MarketData fetches the stock's orderbook and quote at the same time, for
+// when you need both to make up your mind and don't want to wait for two
+// round trips. If either fails, that error is returned (the orderbook's, if
+// both do).
+func (c *Client) MarketData(ctx context.Context, venue, stock string) (*OrderBook, *StockQuote, error) {
+	var quote *StockQuote
+	var quoteErr error
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		quote, quoteErr = c.quoteStock(ctx, venue, stock)
+	}()
+
+	book, err := c.getStockOrderbook(ctx, venue, stock)
+	<-done
+
+	if err != nil {
+		return nil, nil, err
+	}
+	if quoteErr != nil {
+		return nil, nil, quoteErr
+	}
+
+	return book, quote, nil
+}
+
+// GetStockOrderbookMinDepth fetches the stock's orderbook, and fetches it
+// again (every PollInterval, up to retries more times) until there are at
+// least minLevels price levels on both sides, e.g. while a venue that's
+// just opened fills up. If it never gets there, the deepest book it saw
+// (going by its thinner side) is returned, without an error; check it.
+func (c *Client) GetStockOrderbookMinDepth(ctx context.Context, venue, stock string, minLevels int, retries int) (*OrderBook, error) {
+	var best *OrderBook
+	bestLevels := -1
+
+	for attempt := 0; ; attempt++ {
+		book, err := c.getStockOrderbook(ctx, venue, stock)
+		if err != nil {
+			return nil, err
+		}
+
+		levels := min(len(depth(book.sorted(Buy))), len(depth(book.sorted(Sell))))
+		if levels >= minLevels {
+			return book, nil
+		}
+		if levels > bestLevels {
+			best, bestLevels = book, levels
+		}
+
+		if attempt >= retries {
+			return best, nil
+		}
+		if !sleep(ctx, c.pollInterval()) {
+			return nil, ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,281 @@
+package starfighter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAllOpenOrders(t *testing.T) {
+	c, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case fmt.Sprintf("/venues/ONEEX/accounts/%s/orders", TestAccount):
+			fmt.Fprint(w, `{"ok": true, "orders": [
+				{"id": 1, "venue": "ONEEX", "open": true},
+				{"id": 2, "venue": "ONEEX", "open": false}
+			]}`)
+		case fmt.Sprintf("/venues/TWOEX/accounts/%s/orders", TestAccount):
+			fmt.Fprint(w, `{"ok": true, "orders": [
+				{"id": 3, "venue": "TWOEX", "open": false},
+				{"id": 4, "venue": "TWOEX", "open": true},
+				{"id": 5, "venue": "TWOEX", "open": true}
+			]}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"ok": false, "error": "No venue exists with the symbol DOWNEX"}`)
+		}
+	})
+	defer server.Close()
+
+	orders, err := c.AllOpenOrders(context.Background(), TestAccount, []string{"ONEEX", "TWOEX"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ids := []int{}
+	for _, order := range orders {
+		ids = append(ids, order.ID)
+	}
+	if fmt.Sprint(ids) != "[1 4 5]" {
+		t.Errorf("expected open orders [1 4 5], got %v", ids)
+	}
+
+	orders, err = c.AllOpenOrders(context.Background(), TestAccount, []string{"ONEEX", "DOWNEX"})
+	venueErrs, ok := err.(VenueErrors)
+	if !ok || len(venueErrs) != 1 || venueErrs["DOWNEX"] == nil {
+		t.Errorf("expected DOWNEX to fail, got %v", err)
+	}
+	if len(orders) != 1 || orders[0].ID != 1 {
+		t.Errorf("expected ONEEX's open order anyway, got %+v", orders)
+	}
+}
+
+func TestAllOpenOrdersFailFast(t *testing.T) {
+	cancelled := make(chan struct{})
+	c, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/venues/DOWNEX/") {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"ok": false, "error": "No venue exists with the symbol DOWNEX"}`)
+			return
+		}
+
+		// the slow venues only answer once they're given up on
+		select {
+		case <-r.Context().Done():
+			cancelled <- struct{}{}
+		case <-time.After(5 * time.Second):
+			fmt.Fprint(w, `{"ok": true, "orders": []}`)
+		}
+	})
+	defer server.Close()
+	c.FailFast = true
+
+	start := time.Now()
+	orders, err := c.AllOpenOrders(context.Background(), TestAccount, []string{"ONEEX", "DOWNEX", "TWOEX"})
+	venueErrs, ok := err.(VenueErrors)
+	if !ok || len(venueErrs) != 1 || venueErrs["DOWNEX"] == nil {
+		t.Errorf("expected just DOWNEX's error, got %v", err)
+	}
+	if orders != nil {
+		t.Errorf("expected no orders, got %+v", orders)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("expected to give up right away, took %v", elapsed)
+	}
+
+	for k := 0; k < 2; k++ {
+		select {
+		case <-cancelled:
+		case <-time.After(time.Second):
+			t.Fatal("expected the other venues to be cancelled")
+		}
+	}
+}
+
+func TestListAllStocks(t *testing.T) {
+	c, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/venues/ONEEX/stocks":
+			fmt.Fprint(w, `{"ok": true, "symbols": [
+				{"name": "Foo Bar Inc", "symbol": "FOOBAR"},
+				{"name": "Baz Corp", "symbol": "BAZ"},
+				{"name": "Foo Bar Inc", "symbol": "FOOBAR"}
+			]}`)
+		case "/venues/TWOEX/stocks":
+			fmt.Fprint(w, `{"ok": true, "symbols": [{"name": "Foo Bar Inc", "symbol": "FOOBAR"}]}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"ok": false, "error": "No venue exists with the symbol DOWNEX"}`)
+		}
+	})
+	defer server.Close()
+
+	stocks, err := c.ListAllStocks(context.Background(), []string{"ONEEX", "TWOEX"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	listed := []string{}
+	for _, stock := range stocks {
+		listed = append(listed, stock.Venue+"/"+stock.Symbol)
+	}
+	if fmt.Sprint(listed) != "[ONEEX/FOOBAR ONEEX/BAZ TWOEX/FOOBAR]" {
+		t.Errorf("unexpected stocks %v", listed)
+	}
+
+	stocks, err = c.ListAllStocks(context.Background(), []string{"ONEEX", "DOWNEX"})
+	if venueErrs, ok := err.(VenueErrors); !ok || venueErrs["DOWNEX"] == nil {
+		t.Errorf("expected DOWNEX to fail, got %v", err)
+	}
+	if len(stocks) != 2 {
+		t.Errorf("expected ONEEX's stocks anyway, got %+v", stocks)
+	}
+}
+
+func TestGetVenueOrderbooks(t *testing.T) {
+	c, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"ok": true, "symbol": %q, "bids": [{"price": 100, "qty": 1, "isBuy": true}]}`, path.Base(r.URL.Path))
+	})
+	defer server.Close()
+
+	stocks := []string{"A", "B", "C", "D", "E", "F", "G", "H", "I", "J", "K"}
+	books, err := c.GetVenueOrderbooks(context.Background(), TestExchange, stocks)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(books) != len(stocks) {
+		t.Fatalf("expected %d books, got %d", len(stocks), len(books))
+	}
+	for _, stock := range stocks {
+		if books[stock] == nil || books[stock].Symbol != stock {
+			t.Errorf("expected %s's book, got %+v", stock, books[stock])
+		}
+	}
+}
+
+func TestGetVenueOrderbooksCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var fetches int64
+	release := make(chan struct{})
+	c, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		// the first fetch cancels everything; the rest hang until released
+		if atomic.AddInt64(&fetches, 1) == 1 {
+			cancel()
+		}
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+		fmt.Fprint(w, `{"ok": true}`)
+	})
+	defer server.Close()
+	defer close(release)
+
+	before := runtime.NumGoroutine()
+
+	stocks := make([]string, batchConcurrency*4)
+	for k := range stocks {
+		stocks[k] = fmt.Sprintf("S%d", k)
+	}
+
+	_, err := c.GetVenueOrderbooks(ctx, TestExchange, stocks)
+	if err != context.Canceled {
+		t.Errorf("expected the context's error, got %v", err)
+	}
+
+	if n := atomic.LoadInt64(&fetches); n > batchConcurrency {
+		t.Errorf("expected no fetches after cancel, got %d", n)
+	}
+
+	// give anything left over a moment to wind down before counting; dials
+	// that were under way when the fetches gave up can leave idle
+	// connections in the pool, which aren't ours
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+		http.DefaultTransport.(*http.Transport).CloseIdleConnections()
+		time.Sleep(10 * time.Millisecond)
+	}
+	if after := runtime.NumGoroutine(); after > before {
+		t.Errorf("expected no leaked goroutines, had %d before and %d after", before, after)
+	}
+}
+
+func TestGetStockOrderbookMinDepth(t *testing.T) {
+	var fetches int32
+	c, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		book := testBook()
+		// only the touch to start with
+		if atomic.AddInt32(&fetches, 1) == 1 {
+			book.Bids, book.Asks = book.Bids[:1], book.Asks[:1]
+		}
+		json.NewEncoder(w).Encode(book)
+	})
+	defer server.Close()
+	c.PollInterval = time.Millisecond
+
+	book, err := c.GetStockOrderbookMinDepth(context.Background(), TestExchange, TestStock, 3, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(book.Bids) != 3 || len(book.Asks) != 3 || atomic.LoadInt32(&fetches) != 2 {
+		t.Errorf("expected the full book on the second fetch, got %+v after %d", book, fetches)
+	}
+
+	// never deep enough: the best there was comes back
+	atomic.StoreInt32(&fetches, 0)
+	book, err = c.GetStockOrderbookMinDepth(context.Background(), TestExchange, TestStock, 4, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(book.Bids) != 3 || atomic.LoadInt32(&fetches) != 3 {
+		t.Errorf("expected the deepest book after 3 fetches, got %+v after %d", book, fetches)
+	}
+}
+
+func TestMarketData(t *testing.T) {
+	// neither request is answered until both have arrived
+	arrived := make(chan struct{}, 2)
+	both := make(chan struct{})
+	var once sync.Once
+
+	c, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		arrived <- struct{}{}
+		if len(arrived) == 2 {
+			once.Do(func() { close(both) })
+		}
+
+		select {
+		case <-both:
+		case <-time.After(time.Second):
+			w.WriteHeader(http.StatusGatewayTimeout)
+			fmt.Fprint(w, `{"ok": false, "error": "fetched one at a time"}`)
+			return
+		}
+
+		if strings.HasSuffix(r.URL.Path, "/quote") {
+			fmt.Fprintf(w, `{"ok": true, "symbol": %q, "bid": 100, "ask": 102}`, TestStock)
+			return
+		}
+		fmt.Fprintf(w, `{"ok": true, "symbol": %q, "bids": [{"price": 100, "qty": 10, "isBuy": true}]}`, TestStock)
+	})
+	defer server.Close()
+
+	book, quote, err := c.MarketData(context.Background(), TestExchange, TestStock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(book.Bids) != 1 || quote.Ask != 102 {
+		t.Errorf("unexpected book %+v and quote %+v", book, quote)
+	}
+}
@@ -0,0 +1,68 @@
+package starfighter
+
+import (
+	"math"
+	"sync"
+)
+
+// VolatilityTracker keeps the realized volatility of a stock's last trade
+// price: the standard deviation of the log returns between the last few
+// quotes. It's per quote, not annualized; strategies only need it to scale
+// things by. Feed it from the tickertape; it's safe to do so from several
+// goroutines.
+type VolatilityTracker struct {
+	mu      sync.Mutex
+	window  int
+	last    int
+	returns []float64
+}
+
+// NewVolatilityTracker creates a VolatilityTracker over the last window
+// returns (so window+1 quotes).
+func NewVolatilityTracker(window int) *VolatilityTracker {
+	return &VolatilityTracker{window: window}
+}
+
+// AddQuote adds the return since the previous quote. Quotes with no last
+// trade are skipped.
+func (v *VolatilityTracker) AddQuote(quote StockQuote) {
+	if quote.Last <= 0 {
+		return
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if v.last > 0 {
+		v.returns = append(v.returns, math.Log(float64(quote.Last)/float64(v.last)))
+		if len(v.returns) > v.window {
+			v.returns = v.returns[len(v.returns)-v.window:]
+		}
+	}
+	v.last = quote.Last
+}
+
+// Volatility is the sample standard deviation of the returns in the window,
+// or 0 until there are two of them.
+func (v *VolatilityTracker) Volatility() float64 {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	n := len(v.returns)
+	if n < 2 {
+		return 0
+	}
+
+	mean := 0.0
+	for _, r := range v.returns {
+		mean += r
+	}
+	mean /= float64(n)
+
+	variance := 0.0
+	for _, r := range v.returns {
+		variance += (r - mean) * (r - mean)
+	}
+
+	return math.Sqrt(variance / float64(n-1))
+}
@@ -0,0 +1,36 @@
+package starfighter
+
+import (
+	"math"
+	"testing"
+)
+
+func TestVolatilityTracker(t *testing.T) {
+	v := NewVolatilityTracker(3)
+
+	// doesn't move until it does
+	for _, last := range []int{100, 0, 100, 100} {
+		v.AddQuote(StockQuote{Last: last})
+	}
+	if vol := v.Volatility(); vol != 0 {
+		t.Errorf("expected 0 for a flat price, got %v", vol)
+	}
+
+	// up 10%, down 10%, up 10%ish: the flat returns fall out of the window
+	for _, last := range []int{110, 99, 109} {
+		v.AddQuote(StockQuote{Last: last})
+	}
+
+	returns := []float64{math.Log(110.0 / 100), math.Log(99.0 / 110), math.Log(109.0 / 99)}
+	mean := (returns[0] + returns[1] + returns[2]) / 3
+	variance := 0.0
+	for _, r := range returns {
+		variance += (r - mean) * (r - mean)
+	}
+	expected := math.Sqrt(variance / 2)
+
+	// about 0.11
+	if vol := v.Volatility(); math.Abs(vol-expected) > 1e-9 || math.Abs(vol-0.11) > 0.01 {
+		t.Errorf("expected about %v, got %v", expected, vol)
+	}
+}
@@ -0,0 +1,34 @@
+package starfighter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Warmup opens conns connections to the API ahead of time by sending that
+// many venue heartbeats at once, and leaves them idle in the HTTP client's
+// pool, so the orders you send right after don't each wait on a TCP and TLS
+// handshake. The heartbeats go through the client orders use, OrderClient
+// if it's set, so it's that pool that's warmed. The pool only keeps so many
+// idle connections per host (two, for http.DefaultTransport), so give the
+// client a transport with MaxIdleConnsPerHost at least conns if you want
+// them all kept.
+func (c *Client) Warmup(ctx context.Context, venue string, conns int) error {
+	client := c.httpClient("POST")
+	errs := make([]error, conns)
+	var wg sync.WaitGroup
+
+	for k := 0; k < conns; k++ {
+		wg.Add(1)
+		go func(k int) {
+			defer wg.Done()
+			_, _, errs[k] = c.call(ctx, client, "GET", fmt.Sprintf("/venues/%s/heartbeat", venue), nil)
+		}(k)
+	}
+
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
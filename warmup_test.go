@@ -0,0 +1,95 @@
+package starfighter
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWarmup(t *testing.T) {
+	const conns = 4
+
+	// hold every request until all of them are in, so the warmup has to
+	// open a connection for each
+	var mu sync.Mutex
+	waiting := 0
+	all := make(chan struct{})
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		if waiting++; waiting == conns {
+			close(all)
+		}
+		mu.Unlock()
+
+		select {
+		case <-all:
+		case <-time.After(time.Second):
+		}
+		fmt.Fprint(w, `{"ok": true}`)
+	}))
+	opened := int64(0)
+	server.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt64(&opened, 1)
+		}
+	}
+	server.Start()
+	defer server.Close()
+
+	transport := &http.Transport{MaxIdleConnsPerHost: conns}
+	defer transport.CloseIdleConnections()
+	c := &Client{Location: server.URL, Client: http.Client{Transport: transport}}
+
+	if err := c.Warmup(context.Background(), TestExchange, conns); err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.LoadInt64(&opened); n != conns {
+		t.Fatalf("expected the warmup to open %d connections, got %d", conns, n)
+	}
+
+	// a burst that needs as many connections at once finds them ready
+	var wg sync.WaitGroup
+	for k := 0; k < conns; k++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if !c.VenueHealthCheck(TestExchange) {
+				t.Error("expected the venue to be up")
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := atomic.LoadInt64(&opened); n != conns {
+		t.Errorf("expected the burst to reuse the warm connections, but %d more were opened", n-conns)
+	}
+}
+
+func TestWarmupOrderClient(t *testing.T) {
+	c, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"ok": true}`)
+	})
+	defer server.Close()
+
+	orders := &countingTransport{counts: map[string]int{}}
+	reads := &countingTransport{counts: map[string]int{}}
+	c.OrderClient = &http.Client{Transport: orders}
+	c.ReadClient = &http.Client{Transport: reads}
+
+	if err := c.Warmup(context.Background(), TestExchange, 3); err != nil {
+		t.Fatal(err)
+	}
+	if orders.counts["GET"] != 3 {
+		t.Errorf("expected the warmup to go through the order client, got %v", orders.counts)
+	}
+	if len(reads.counts) != 0 {
+		t.Errorf("expected nothing through the read client, got %v", reads.counts)
+	}
+}
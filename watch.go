@@ -0,0 +1,72 @@
+package starfighter
+
+import (
+	"context"
+	"time"
+)
+
+// WaitForPrice polls the stock's quote every poll (PollInterval if poll is
+// zero or less) until predicate is true of it, and returns that quote. It
+// gives up when ctx is done or a quote fails.
+func (c *Client) WaitForPrice(ctx context.Context, venue, stock string, predicate func(*StockQuote) bool, poll time.Duration) (*StockQuote, error) {
+	if poll <= 0 {
+		poll = c.pollInterval()
+	}
+	ticker := time.NewTicker(poll)
+	defer ticker.Stop()
+
+	for {
+		quote, err := c.quoteStock(ctx, venue, stock)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			return nil, err
+		}
+
+		if predicate(quote) {
+			return quote, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// WatchSpread polls the stock's quote every PollInterval and calls onWide
+// when the spread goes over threshold cents. It only calls again once the
+// spread has come back in, so a book that stays wide is one alert, not one
+// per poll. Quotes missing a side don't count either way. It runs until ctx
+// is done or a quote fails.
+func (c *Client) WatchSpread(ctx context.Context, venue, stock string, threshold int, onWide func(*StockQuote)) error {
+	ticker := time.NewTicker(c.pollInterval())
+	defer ticker.Stop()
+
+	wide := false
+	for {
+		quote, err := c.quoteStock(ctx, venue, stock)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+
+		if quote.Bid > 0 && quote.Ask > 0 {
+			spread := quote.Ask - quote.Bid
+			if spread > threshold && !wide {
+				onWide(quote)
+			}
+			wide = spread > threshold
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package starfighter
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newRisingQuoteClient serves quotes whose last price goes up by step each time.
+func newRisingQuoteClient(start, step int) (*Client, func()) {
+	calls := int64(0)
+	c, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt64(&calls, 1) - 1
+		fmt.Fprintf(w, `{"ok": true, "symbol": %q, "last": %d}`, TestStock, start+int(n)*step)
+	})
+	return c, server.Close
+}
+
+func TestWaitForPrice(t *testing.T) {
+	c, closeServer := newRisingQuoteClient(100, 10)
+	defer closeServer()
+
+	quote, err := c.WaitForPrice(context.Background(), TestExchange, TestStock, func(q *StockQuote) bool {
+		return q.Last >= 115
+	}, time.Millisecond)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	if quote.Last != 120 {
+		t.Errorf("expected the first quote over 115 (120), got %d", quote.Last)
+	}
+}
+
+func TestWaitForPriceDefaultPoll(t *testing.T) {
+	c, closeServer := newRisingQuoteClient(100, 10)
+	defer closeServer()
+	c.PollInterval = time.Millisecond
+
+	quote, err := c.WaitForPrice(context.Background(), TestExchange, TestStock, func(q *StockQuote) bool {
+		return q.Last >= 115
+	}, 0)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	if quote.Last != 120 {
+		t.Errorf("expected the first quote over 115 (120), got %d", quote.Last)
+	}
+}
+
+func TestWaitForPriceCancel(t *testing.T) {
+	c, closeServer := newRisingQuoteClient(100, 0)
+	defer closeServer()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err := c.WaitForPrice(ctx, TestExchange, TestStock, func(q *StockQuote) bool {
+		return q.Last > 100
+	}, time.Millisecond)
+
+	if err != context.DeadlineExceeded {
+		t.Errorf("expected the deadline to be exceeded, got %v", err)
+	}
+}
+
+func TestWatchSpread(t *testing.T) {
+	// tight, wide, wide, tight, wide, then tight from there on
+	spreads := []int{2, 10, 12, 3, 8}
+	calls := int64(0)
+	c, server := newTestClient(func(w http.ResponseWriter, r *http.Request) {
+		n := int(atomic.AddInt64(&calls, 1) - 1)
+		spread := 1
+		if n < len(spreads) {
+			spread = spreads[n]
+		}
+		fmt.Fprintf(w, `{"ok": true, "symbol": %q, "bid": 100, "ask": %d}`, TestStock, 100+spread)
+	})
+	defer server.Close()
+	c.PollInterval = time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var alerts []int
+	err := c.WatchSpread(ctx, TestExchange, TestStock, 5, func(q *StockQuote) {
+		alerts = append(alerts, q.Ask-q.Bid)
+		if len(alerts) == 2 {
+			cancel()
+		}
+	})
+
+	if err != context.Canceled {
+		t.Errorf("expected to stop on cancel, got %v", err)
+	}
+	if len(alerts) != 2 || alerts[0] != 10 || alerts[1] != 8 {
+		t.Errorf("expected alerts at spreads 10 and 8, got %v", alerts)
+	}
+}
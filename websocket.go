@@ -0,0 +1,249 @@
+package starfighter
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// just enough of RFC 6455 to read the API's feeds.
+const (
+	wsGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+	wsContinuation = 0x0
+	wsText         = 0x1
+	wsBinary       = 0x2
+	wsClose        = 0x8
+	wsPing         = 0x9
+	wsPong         = 0xA
+
+	// nothing the API sends comes close to this
+	wsMaxFrame = 1 << 24
+)
+
+// ErrNoUpgrade is returned when the HTTP client's transport hands back a
+// response body that can't be written to, so it can't carry a websocket.
+var ErrNoUpgrade = errors.New("starfighter: transport does not support websocket upgrades")
+
+// wsConn is a client websocket connection.
+type wsConn struct {
+	rw io.ReadWriteCloser
+	r  *bufio.Reader
+
+	// guards writes, which come from both the reader (pongs) and Close
+	mu        sync.Mutex
+	closeOnce sync.Once
+}
+
+// dialFeed opens a websocket to the endpoint (without the location).
+func (c *Client) dialFeed(ctx context.Context, endpoint string) (*wsConn, error) {
+	req, err := http.NewRequest("GET", c.Location+endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req = req.WithContext(ctx)
+
+	nonce := make([]byte, 16)
+	if _, err = rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	key := base64.StdEncoding.EncodeToString(nonce)
+
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Sec-WebSocket-Key", key)
+	c.setHeaders(req)
+
+	// a client timeout would cut the feed off once it expires
+	client := c.Client
+	client.Timeout = 0
+
+	resp, err := c.withDialTimeout(&client).Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		defer resp.Body.Close()
+
+		body := map[string]interface{}{}
+		json.NewDecoder(resp.Body).Decode(&body)
+		message, _ := body["error"].(string)
+		if message == "" {
+			message = fmt.Sprintf("websocket handshake failed: %s", resp.Status)
+		}
+
+		return nil, newAPIError(resp.StatusCode, message)
+	}
+
+	rw, ok := resp.Body.(io.ReadWriteCloser)
+	if !ok {
+		resp.Body.Close()
+		return nil, ErrNoUpgrade
+	}
+
+	if resp.Header.Get("Sec-WebSocket-Accept") != wsAccept(key) {
+		rw.Close()
+		return nil, errors.New("starfighter: bad websocket accept key")
+	}
+
+	return &wsConn{
+		rw: rw,
+		r:  bufio.NewReader(rw),
+	}, nil
+}
+
+// ReadMessage reads the next text or binary message, answering pings on
+// the way. It returns io.EOF once the server closes the connection.
+func (ws *wsConn) ReadMessage() ([]byte, error) {
+	var message []byte
+
+	for {
+		fin, opcode, payload, err := ws.readFrame()
+		if err != nil {
+			return nil, err
+		}
+
+		switch opcode {
+		case wsPing:
+			if err = ws.write(wsPong, payload); err != nil {
+				return nil, err
+			}
+			continue
+		case wsPong:
+			continue
+		case wsClose:
+			ws.Close()
+			return nil, io.EOF
+		}
+
+		message = append(message, payload...)
+		if fin {
+			return message, nil
+		}
+	}
+}
+
+// Close tells the server we're going away and closes the connection.
+func (ws *wsConn) Close() error {
+	err := error(nil)
+	ws.closeOnce.Do(func() {
+		ws.write(wsClose, nil)
+		err = ws.rw.Close()
+	})
+	return err
+}
+
+func (ws *wsConn) readFrame() (fin bool, opcode byte, payload []byte, err error) {
+	header := make([]byte, 2)
+	if _, err = io.ReadFull(ws.r, header); err != nil {
+		return
+	}
+
+	fin = header[0]&0x80 != 0
+	opcode = header[0] & 0x0f
+	masked := header[1]&0x80 != 0
+	length := uint64(header[1] & 0x7f)
+
+	switch length {
+	case 126:
+		extended := make([]byte, 2)
+		if _, err = io.ReadFull(ws.r, extended); err != nil {
+			return
+		}
+		length = uint64(binary.BigEndian.Uint16(extended))
+	case 127:
+		extended := make([]byte, 8)
+		if _, err = io.ReadFull(ws.r, extended); err != nil {
+			return
+		}
+		length = binary.BigEndian.Uint64(extended)
+	}
+
+	if length > wsMaxFrame {
+		err = fmt.Errorf("starfighter: websocket frame too large (%d bytes)", length)
+		return
+	}
+
+	mask := make([]byte, 4)
+	if masked {
+		if _, err = io.ReadFull(ws.r, mask); err != nil {
+			return
+		}
+	}
+
+	payload = make([]byte, length)
+	if _, err = io.ReadFull(ws.r, payload); err != nil {
+		return
+	}
+
+	if masked {
+		for k := range payload {
+			payload[k] ^= mask[k%4]
+		}
+	}
+
+	return
+}
+
+func (ws *wsConn) write(opcode byte, payload []byte) error {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	return writeFrame(ws.rw, opcode, payload, true)
+}
+
+// writeFrame writes a single, final frame. Clients must mask their frames;
+// servers must not.
+func writeFrame(w io.Writer, opcode byte, payload []byte, masked bool) error {
+	frame := []byte{0x80 | opcode}
+
+	maskBit := byte(0)
+	if masked {
+		maskBit = 0x80
+	}
+
+	switch length := len(payload); {
+	case length < 126:
+		frame = append(frame, maskBit|byte(length))
+	case length <= 0xffff:
+		frame = append(frame, maskBit|126, 0, 0)
+		binary.BigEndian.PutUint16(frame[2:], uint16(length))
+	default:
+		frame = append(frame, maskBit|127, 0, 0, 0, 0, 0, 0, 0, 0)
+		binary.BigEndian.PutUint64(frame[2:], uint64(length))
+	}
+
+	if masked {
+		mask := make([]byte, 4)
+		if _, err := rand.Read(mask); err != nil {
+			return err
+		}
+		frame = append(frame, mask...)
+
+		start := len(frame)
+		frame = append(frame, payload...)
+		for k := range payload {
+			frame[start+k] ^= mask[k%4]
+		}
+	} else {
+		frame = append(frame, payload...)
+	}
+
+	_, err := w.Write(frame)
+	return err
+}
+
+func wsAccept(key string) string {
+	sum := sha1.Sum([]byte(key + wsGUID))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}